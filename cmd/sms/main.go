@@ -78,8 +78,12 @@ var cmdShortMessage = cli.Command{
 		},
 		cli.StringFlag{
 			Name:  "encoding",
-			Usage: "set text encoding: raw, ucs2 or latin1",
-			Value: "raw",
+			Usage: "set text encoding: auto, auto-translit, raw, ucs2 or latin1",
+			Value: "auto",
+		},
+		cli.BoolFlag{
+			Name:  "flash",
+			Usage: "send as flash message (class 0), in GSM 7-bit or UCS2",
 		},
 		cli.StringFlag{
 			Name:  "service-type",
@@ -161,8 +165,19 @@ var cmdShortMessage = cli.Command{
 			codec = pdutext.UCS2(text)
 		case "latin1", "latin-1":
 			codec = pdutext.Latin1(text)
-		default:
+		case "raw":
 			codec = pdutext.Raw(text)
+		case "auto-translit":
+			codec = pdutext.AutoTransliterate(text)
+		default:
+			codec = pdutext.Auto(text)
+		}
+		var dcs *pdutext.DCS
+		if c.Bool("flash") {
+			dcs = &pdutext.DCS{Class: pdutext.Class0}
+			if t := codec.Type(); t != pdutext.DefaultType && t != pdutext.UCS2Type {
+				codec = pdutext.UCS2(text)
+			}
 		}
 		sm, err := tx.Submit(&smpp.ShortMessage{
 			Src:                  sender,
@@ -180,6 +195,7 @@ var cmdShortMessage = cli.Command{
 			ScheduleDeliveryTime: c.String("schedule-delivery-time"),
 			ReplaceIfPresentFlag: uint8(c.Int("replace-if-present-flag")),
 			SMDefaultMsgID:       uint8(c.Int("sm-default-msg-id")),
+			DCS:                  dcs,
 		})
 		if err != nil {
 			log.Fatalln("Failed:", err)
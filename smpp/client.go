@@ -72,7 +72,10 @@ type ClientConn interface {
 //
 // The Transmitter or Transceiver using the RateLimiter holds a
 // single context.Context per client connection, passed to Wait
-// prior to sending short messages.
+// prior to sending short messages. Methods that take a context,
+// such as SubmitContext, pass their own context to Wait instead,
+// and return its error if the context is done before the limiter
+// permits the event.
 //
 // Suitable for use with package golang.org/x/time/rate.
 type RateLimiter interface {
@@ -85,12 +88,15 @@ type client struct {
 	Addr               string
 	TLS                *tls.Config
 	Status             chan ConnStatus
+	DialFunc           func() (Conn, error)
 	BindFunc           func(c Conn) error
 	EnquireLink        time.Duration
 	EnquireLinkTimeout time.Duration
 	RespTimeout        time.Duration
 	BindInterval       time.Duration
 	WindowSize         uint
+	WindowWait         bool
+	Tracker            *ReceiptTracker
 	RateLimiter        RateLimiter
 
 	UnknownPDUDecoder UnknownPDUDecoder
@@ -101,6 +107,8 @@ type client struct {
 	stop  chan struct{}
 	once  sync.Once
 	lmctx context.Context
+	// window of inflight requests, set if WindowSize > 0
+	window *window
 	// time of the last received EnquireLinkResp
 	eliTime time.Time
 	eliMtx  sync.RWMutex
@@ -113,6 +121,9 @@ func (c *client) init() {
 	if c.RateLimiter != nil {
 		c.lmctx = context.Background()
 	}
+	if c.WindowSize > 0 {
+		c.window = newWindow(c.WindowSize)
+	}
 	if c.EnquireLink < 10*time.Second {
 		c.EnquireLink = 10 * time.Second
 	}
@@ -130,7 +141,7 @@ func (c *client) Bind() {
 	for !c.closed() {
 		eli := make(chan struct{})
 		c.inbox = make(chan pdu.Body)
-		conn, err := Dial(c.Addr, c.TLS)
+		conn, err := c.dial()
 		if err != nil {
 			c.notify(&connStatus{
 				s:   ConnectionFailed,
@@ -182,6 +193,15 @@ func (c *client) Bind() {
 	close(c.Status)
 }
 
+// dial returns a new connection using DialFunc if set, or
+// dials to Addr otherwise.
+func (c *client) dial() (Conn, error) {
+	if c.DialFunc != nil {
+		return c.DialFunc()
+	}
+	return Dial(c.Addr, c.TLS)
+}
+
 func (c *client) enquireLink(stop chan struct{}) {
 	// for the first check set time as Now()
 	c.updateEliTime()
@@ -241,6 +261,17 @@ func (c *client) Write(w pdu.Body) error {
 	return c.conn.Write(w)
 }
 
+// writeContext is like Write but waits on the rate limiter using the
+// given context, returning its error if the wait is interrupted.
+func (c *client) writeContext(ctx context.Context, w pdu.Body) error {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return c.conn.Write(w)
+}
+
 // Close terminates the current connection and stop any further attempts.
 func (c *client) Close() error {
 	c.once.Do(func() {
@@ -277,10 +308,16 @@ func (c *client) closed() bool {
 // respTimeout returns a channel that fires based on the configured
 // response timeout, or the default 1s.
 func (c *client) respTimeout() <-chan time.Time {
+	return time.After(c.respTimeoutDuration())
+}
+
+// respTimeoutDuration returns the configured response timeout, or the
+// default 1s.
+func (c *client) respTimeoutDuration() time.Duration {
 	if c.RespTimeout == 0 {
-		return time.After(time.Second)
+		return time.Second
 	}
-	return time.After(c.RespTimeout)
+	return c.RespTimeout
 }
 
 // bind attempts to bind the connection.
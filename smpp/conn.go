@@ -69,12 +69,7 @@ func Dial(addr string, TLS *tls.Config) (Conn, error) {
 	if TLS != nil {
 		fd = tls.Client(fd, TLS)
 	}
-	c := &conn{
-		rwc: fd,
-		r:   bufio.NewReader(fd),
-		w:   bufio.NewWriter(fd),
-	}
-	return c, nil
+	return newConn(fd), nil
 }
 
 // conn provides the basics of a single client connection and
@@ -86,6 +81,14 @@ type conn struct {
 	unknownPDUDecoder UnknownPDUDecoder
 }
 
+func newConn(fd net.Conn) *conn {
+	return &conn{
+		rwc: fd,
+		r:   bufio.NewReader(fd),
+		w:   bufio.NewWriter(fd),
+	}
+}
+
 // Read implements the Conn interface.
 func (c *conn) Read() (pdu.Body, error) {
 	pduBody, header, raw, err := pdu.Decode(c.r)
@@ -69,6 +69,22 @@ var reverseEscape = map[byte]rune{
 	0x0A: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2F: '\\', 0x3C: '[', 0x3D: '~', 0x3E: ']', 0x40: '|', 0x65: '€',
 }
 
+// gsm7Tables contains the lookup tables of a GSM 7-bit alphabet and
+// its extension table.
+type gsm7Tables struct {
+	forward       map[rune]byte
+	forwardEscape map[rune]byte
+	reverse       map[byte]rune
+	reverseEscape map[byte]rune
+}
+
+var defaultTables = &gsm7Tables{
+	forward:       forwardLookup,
+	forwardEscape: forwardEscape,
+	reverse:       reverseLookup,
+	reverseEscape: reverseEscape,
+}
+
 // Returns the characters, in the given text, that can not be represented in GSM 7-bit encoding.
 func ValidateGSM7String(text string) []rune {
 	invalidChars := make([]rune, 0, 4)
@@ -111,22 +127,25 @@ func ValidateGSM7Buffer(buffer []byte) []byte {
 // Set the packed flag to true if you wish to convert septets to octets,
 // this should be false for most SMPP providers.
 func GSM7(packed bool) encoding.Encoding {
-	return gsm7Encoding{packed: packed}
+	return gsm7Encoding{packed: packed, tables: defaultTables}
 }
 
 type gsm7Encoding struct {
 	packed bool
+	tables *gsm7Tables
 }
 
 func (g gsm7Encoding) NewDecoder() *encoding.Decoder {
 	return &encoding.Decoder{Transformer: &gsm7Decoder{
 		packed: g.packed,
+		tables: g.tables,
 	}}
 }
 
 func (g gsm7Encoding) NewEncoder() *encoding.Encoder {
 	return &encoding.Encoder{Transformer: &gsm7Encoder{
 		packed: g.packed,
+		tables: g.tables,
 	}}
 }
 
@@ -139,6 +158,7 @@ func (g gsm7Encoding) String() string {
 
 type gsm7Decoder struct {
 	packed bool
+	tables *gsm7Tables
 }
 
 func (g *gsm7Decoder) Reset() {
@@ -219,12 +239,12 @@ func (g *gsm7Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 				return 0, 0, ErrInvalidByte
 			}
 			e := septets[nSeptet]
-			if r, ok := reverseEscape[e]; ok {
+			if r, ok := g.tables.reverseEscape[e]; ok {
 				builder.WriteRune(r)
 			} else {
 				return 0, 0, ErrInvalidByte
 			}
-		} else if r, ok := reverseLookup[b]; ok {
+		} else if r, ok := g.tables.reverse[b]; ok {
 			builder.WriteRune(r)
 		} else {
 			return 0, 0, ErrInvalidByte
@@ -246,6 +266,7 @@ func (g *gsm7Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 
 type gsm7Encoder struct {
 	packed bool
+	tables *gsm7Tables
 }
 
 func (g *gsm7Encoder) Reset() {
@@ -260,9 +281,9 @@ func (g *gsm7Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, er
 	text := string(src) // work with []rune (a.k.a string) instead of []byte
 	septets := make([]byte, 0, len(text))
 	for _, r := range text {
-		if v, ok := forwardLookup[r]; ok {
+		if v, ok := g.tables.forward[r]; ok {
 			septets = append(septets, v)
-		} else if v, ok := forwardEscape[r]; ok {
+		} else if v, ok := g.tables.forwardEscape[r]; ok {
 			septets = append(septets, escapeSequence, v)
 		} else {
 			return 0, 0, ErrInvalidCharacter
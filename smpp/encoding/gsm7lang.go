@@ -0,0 +1,557 @@
+package encoding
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/text/encoding"
+)
+
+// Language identifies a national language shift table of the GSM 7-bit
+// alphabet, as sent in the user data header IEIs 0x24 (single shift)
+// and 0x25 (locking shift).
+type Language uint8
+
+// Supported national languages.
+const (
+	DefaultLanguage Language = 0x00 // Default alphabet and extension table
+	Turkish         Language = 0x01
+	Spanish         Language = 0x02 // Single shift table only
+	Portuguese      Language = 0x03
+	Bengali         Language = 0x04
+	Gujarati        Language = 0x05
+	Hindi           Language = 0x06
+	Kannada         Language = 0x07
+	Malayalam       Language = 0x08
+	Oriya           Language = 0x09
+	Punjabi         Language = 0x0a
+	Tamil           Language = 0x0b
+	Telugu          Language = 0x0c
+	Urdu            Language = 0x0d
+)
+
+var languageText = map[Language]string{
+	DefaultLanguage: "Default",
+	Turkish:         "Turkish",
+	Spanish:         "Spanish",
+	Portuguese:      "Portuguese",
+	Bengali:         "Bengali",
+	Gujarati:        "Gujarati",
+	Hindi:           "Hindi",
+	Kannada:         "Kannada",
+	Malayalam:       "Malayalam",
+	Oriya:           "Oriya",
+	Punjabi:         "Punjabi",
+	Tamil:           "Tamil",
+	Telugu:          "Telugu",
+	Urdu:            "Urdu",
+}
+
+// String implements the fmt.Stringer interface.
+func (l Language) String() string {
+	if s, ok := languageText[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("Language(%d)", uint8(l))
+}
+
+/*
+National language locking shift and single shift tables
+
+Source: 3GPP TS 23.038, annex A
+
+The locking shift tables of the European languages are listed as the
+characters that differ from the default alphabet. The ones of the Indian
+languages replace the whole default alphabet, and are listed in full in
+lockingAlphabet. Positions left undefined by the standard are missing
+from the tables.
+*/
+var lockingShift = map[Language]map[byte]rune{
+	Turkish: {
+		0x04: '€', 0x07: 'ı', 0x0b: 'Ğ', 0x0c: 'ğ', 0x1c: 'Ş', 0x1d: 'ş', 0x40: 'İ', 0x60: 'ç',
+	},
+	Portuguese: {
+		0x04: 'ê', 0x06: 'ú', 0x07: 'í', 0x08: 'ó', 0x09: 'ç', 0x0b: 'Ô', 0x0c: 'ô', 0x0e: 'Á',
+		0x0f: 'á', 0x12: 'ª', 0x13: 'Ç', 0x14: 'À', 0x15: '∞', 0x16: '^', 0x17: '\\', 0x18: '€',
+		0x19: 'Ó', 0x1a: '|', 0x1c: 'Â', 0x1d: 'â', 0x1e: 'Ê', 0x24: 'º', 0x40: 'Í', 0x5b: 'Ã',
+		0x5c: 'Õ', 0x5d: 'Ú', 0x60: '~', 0x7b: 'ã', 0x7c: 'õ', 0x7d: '`',
+	},
+}
+
+var lockingAlphabet = map[Language]map[byte]rune{
+	Bengali: {
+		0x00: '\u0981', 0x01: '\u0982', 0x02: '\u0983', 0x03: '\u0985', 0x04: '\u0986', 0x05: '\u0987',
+		0x06: '\u0988', 0x07: '\u0989', 0x08: '\u098a', 0x09: '\u098b', 0x0a: '\n', 0x0b: '\u098c',
+		0x0d: '\r', 0x0f: '\u098f', 0x10: '\u0990', 0x13: '\u0993', 0x14: '\u0994', 0x15: '\u0995',
+		0x16: '\u0996', 0x17: '\u0997', 0x18: '\u0998', 0x19: '\u0999', 0x1a: '\u099a', 0x1c: '\u099b',
+		0x1d: '\u099c', 0x1e: '\u099d', 0x1f: '\u099e', 0x20: ' ', 0x21: '!', 0x22: '\u099f',
+		0x23: '\u09a0', 0x24: '\u09a1', 0x25: '\u09a2', 0x26: '\u09a3', 0x27: '\u09a4', 0x28: ')',
+		0x29: '(', 0x2a: '\u09a5', 0x2b: '\u09a6', 0x2c: ',', 0x2d: '\u09a7', 0x2e: '.',
+		0x2f: '\u09a8', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4',
+		0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':',
+		0x3b: ';', 0x3d: '\u09aa', 0x3e: '\u09ab', 0x3f: '?', 0x40: '\u09ac', 0x41: '\u09ad',
+		0x42: '\u09ae', 0x43: '\u09af', 0x44: '\u09b0', 0x46: '\u09b2', 0x4a: '\u09b6', 0x4b: '\u09b7',
+		0x4c: '\u09b8', 0x4d: '\u09b9', 0x4e: '\u09bc', 0x4f: '\u09bd', 0x50: '\u09be', 0x51: '\u09bf',
+		0x52: '\u09c0', 0x53: '\u09c1', 0x54: '\u09c2', 0x55: '\u09c3', 0x56: '\u09c4', 0x59: '\u09c7',
+		0x5a: '\u09c8', 0x5d: '\u09cb', 0x5e: '\u09cc', 0x5f: '\u09cd', 0x60: '\u09ce', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u09d7', 0x7c: '\u09dc', 0x7d: '\u09dd', 0x7e: '\u09f0', 0x7f: '\u09f1',
+	},
+	Gujarati: {
+		0x00: '\u0a81', 0x01: '\u0a82', 0x02: '\u0a83', 0x03: '\u0a85', 0x04: '\u0a86', 0x05: '\u0a87',
+		0x06: '\u0a88', 0x07: '\u0a89', 0x08: '\u0a8a', 0x09: '\u0a8b', 0x0a: '\n', 0x0b: '\u0a8c',
+		0x0c: '\u0a8d', 0x0d: '\r', 0x0f: '\u0a8f', 0x10: '\u0a90', 0x11: '\u0a91', 0x13: '\u0a93',
+		0x14: '\u0a94', 0x15: '\u0a95', 0x16: '\u0a96', 0x17: '\u0a97', 0x18: '\u0a98', 0x19: '\u0a99',
+		0x1a: '\u0a9a', 0x1c: '\u0a9b', 0x1d: '\u0a9c', 0x1e: '\u0a9d', 0x1f: '\u0a9e', 0x20: ' ',
+		0x21: '!', 0x22: '\u0a9f', 0x23: '\u0aa0', 0x24: '\u0aa1', 0x25: '\u0aa2', 0x26: '\u0aa3',
+		0x27: '\u0aa4', 0x28: ')', 0x29: '(', 0x2a: '\u0aa5', 0x2b: '\u0aa6', 0x2c: ',',
+		0x2d: '\u0aa7', 0x2e: '.', 0x2f: '\u0aa8', 0x30: '0', 0x31: '1', 0x32: '2',
+		0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+		0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: '\u0aaa', 0x3e: '\u0aab', 0x3f: '?',
+		0x40: '\u0aac', 0x41: '\u0aad', 0x42: '\u0aae', 0x43: '\u0aaf', 0x44: '\u0ab0', 0x46: '\u0ab2',
+		0x47: '\u0ab3', 0x49: '\u0ab5', 0x4a: '\u0ab6', 0x4b: '\u0ab7', 0x4c: '\u0ab8', 0x4d: '\u0ab9',
+		0x4e: '\u0abc', 0x4f: '\u0abd', 0x50: '\u0abe', 0x51: '\u0abf', 0x52: '\u0ac0', 0x53: '\u0ac1',
+		0x54: '\u0ac2', 0x55: '\u0ac3', 0x56: '\u0ac4', 0x57: '\u0ac5', 0x59: '\u0ac7', 0x5a: '\u0ac8',
+		0x5b: '\u0ac9', 0x5d: '\u0acb', 0x5e: '\u0acc', 0x5f: '\u0acd', 0x60: '\u0ad0', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u0ae0', 0x7c: '\u0ae1', 0x7d: '\u0ae2', 0x7e: '\u0ae3', 0x7f: '\u0af1',
+	},
+	Hindi: {
+		0x00: '\u0901', 0x01: '\u0902', 0x02: '\u0903', 0x03: '\u0905', 0x04: '\u0906', 0x05: '\u0907',
+		0x06: '\u0908', 0x07: '\u0909', 0x08: '\u090a', 0x09: '\u090b', 0x0a: '\n', 0x0b: '\u090c',
+		0x0c: '\u090d', 0x0d: '\r', 0x0e: '\u090e', 0x0f: '\u090f', 0x10: '\u0910', 0x11: '\u0911',
+		0x12: '\u0912', 0x13: '\u0913', 0x14: '\u0914', 0x15: '\u0915', 0x16: '\u0916', 0x17: '\u0917',
+		0x18: '\u0918', 0x19: '\u0919', 0x1a: '\u091a', 0x1c: '\u091b', 0x1d: '\u091c', 0x1e: '\u091d',
+		0x1f: '\u091e', 0x20: ' ', 0x21: '!', 0x22: '\u091f', 0x23: '\u0920', 0x24: '\u0921',
+		0x25: '\u0922', 0x26: '\u0923', 0x27: '\u0924', 0x28: ')', 0x29: '(', 0x2a: '\u0925',
+		0x2b: '\u0926', 0x2c: ',', 0x2d: '\u0927', 0x2e: '.', 0x2f: '\u0928', 0x30: '0',
+		0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6',
+		0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: '\u0929',
+		0x3d: '\u092a', 0x3e: '\u092b', 0x3f: '?', 0x40: '\u092c', 0x41: '\u092d', 0x42: '\u092e',
+		0x43: '\u092f', 0x44: '\u0930', 0x45: '\u0931', 0x46: '\u0932', 0x47: '\u0933', 0x48: '\u0934',
+		0x49: '\u0935', 0x4a: '\u0936', 0x4b: '\u0937', 0x4c: '\u0938', 0x4d: '\u0939', 0x4e: '\u093c',
+		0x4f: '\u093d', 0x50: '\u093e', 0x51: '\u093f', 0x52: '\u0940', 0x53: '\u0941', 0x54: '\u0942',
+		0x55: '\u0943', 0x56: '\u0944', 0x57: '\u0945', 0x58: '\u0946', 0x59: '\u0947', 0x5a: '\u0948',
+		0x5b: '\u0949', 0x5c: '\u094a', 0x5d: '\u094b', 0x5e: '\u094c', 0x5f: '\u094d', 0x60: '\u0950',
+		0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f',
+		0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l',
+		0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r',
+		0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+		0x79: 'y', 0x7a: 'z', 0x7b: '\u0972', 0x7c: '\u097b', 0x7d: '\u097c', 0x7e: '\u097e',
+		0x7f: '\u097f',
+	},
+	Kannada: {
+		0x01: '\u0c82', 0x02: '\u0c83', 0x03: '\u0c85', 0x04: '\u0c86', 0x05: '\u0c87', 0x06: '\u0c88',
+		0x07: '\u0c89', 0x08: '\u0c8a', 0x09: '\u0c8b', 0x0a: '\n', 0x0b: '\u0c8c', 0x0d: '\r',
+		0x0e: '\u0c8e', 0x0f: '\u0c8f', 0x10: '\u0c90', 0x12: '\u0c92', 0x13: '\u0c93', 0x14: '\u0c94',
+		0x15: '\u0c95', 0x16: '\u0c96', 0x17: '\u0c97', 0x18: '\u0c98', 0x19: '\u0c99', 0x1a: '\u0c9a',
+		0x1c: '\u0c9b', 0x1d: '\u0c9c', 0x1e: '\u0c9d', 0x1f: '\u0c9e', 0x20: ' ', 0x21: '!',
+		0x22: '\u0c9f', 0x23: '\u0ca0', 0x24: '\u0ca1', 0x25: '\u0ca2', 0x26: '\u0ca3', 0x27: '\u0ca4',
+		0x28: ')', 0x29: '(', 0x2a: '\u0ca5', 0x2b: '\u0ca6', 0x2c: ',', 0x2d: '\u0ca7',
+		0x2e: '.', 0x2f: '\u0ca8', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3',
+		0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9',
+		0x3a: ':', 0x3b: ';', 0x3d: '\u0caa', 0x3e: '\u0cab', 0x3f: '?', 0x40: '\u0cac',
+		0x41: '\u0cad', 0x42: '\u0cae', 0x43: '\u0caf', 0x44: '\u0cb0', 0x45: '\u0cb1', 0x46: '\u0cb2',
+		0x47: '\u0cb3', 0x49: '\u0cb5', 0x4a: '\u0cb6', 0x4b: '\u0cb7', 0x4c: '\u0cb8', 0x4d: '\u0cb9',
+		0x4e: '\u0cbc', 0x4f: '\u0cbd', 0x50: '\u0cbe', 0x51: '\u0cbf', 0x52: '\u0cc0', 0x53: '\u0cc1',
+		0x54: '\u0cc2', 0x55: '\u0cc3', 0x56: '\u0cc4', 0x58: '\u0cc6', 0x59: '\u0cc7', 0x5a: '\u0cc8',
+		0x5c: '\u0cca', 0x5d: '\u0ccb', 0x5e: '\u0ccc', 0x5f: '\u0ccd', 0x60: '\u0cd5', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u0cd6', 0x7c: '\u0ce0', 0x7d: '\u0ce1', 0x7e: '\u0ce2', 0x7f: '\u0ce3',
+	},
+	Malayalam: {
+		0x01: '\u0d02', 0x02: '\u0d03', 0x03: '\u0d05', 0x04: '\u0d06', 0x05: '\u0d07', 0x06: '\u0d08',
+		0x07: '\u0d09', 0x08: '\u0d0a', 0x09: '\u0d0b', 0x0a: '\n', 0x0b: '\u0d0c', 0x0d: '\r',
+		0x0e: '\u0d0e', 0x0f: '\u0d0f', 0x10: '\u0d10', 0x12: '\u0d12', 0x13: '\u0d13', 0x14: '\u0d14',
+		0x15: '\u0d15', 0x16: '\u0d16', 0x17: '\u0d17', 0x18: '\u0d18', 0x19: '\u0d19', 0x1a: '\u0d1a',
+		0x1c: '\u0d1b', 0x1d: '\u0d1c', 0x1e: '\u0d1d', 0x1f: '\u0d1e', 0x20: ' ', 0x21: '!',
+		0x22: '\u0d1f', 0x23: '\u0d20', 0x24: '\u0d21', 0x25: '\u0d22', 0x26: '\u0d23', 0x27: '\u0d24',
+		0x28: ')', 0x29: '(', 0x2a: '\u0d25', 0x2b: '\u0d26', 0x2c: ',', 0x2d: '\u0d27',
+		0x2e: '.', 0x2f: '\u0d28', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3',
+		0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9',
+		0x3a: ':', 0x3b: ';', 0x3d: '\u0d2a', 0x3e: '\u0d2b', 0x3f: '?', 0x40: '\u0d2c',
+		0x41: '\u0d2d', 0x42: '\u0d2e', 0x43: '\u0d2f', 0x44: '\u0d30', 0x45: '\u0d31', 0x46: '\u0d32',
+		0x47: '\u0d33', 0x48: '\u0d34', 0x49: '\u0d35', 0x4a: '\u0d36', 0x4b: '\u0d37', 0x4c: '\u0d38',
+		0x4d: '\u0d39', 0x4f: '\u0d3d', 0x50: '\u0d3e', 0x51: '\u0d3f', 0x52: '\u0d40', 0x53: '\u0d41',
+		0x54: '\u0d42', 0x55: '\u0d43', 0x56: '\u0d44', 0x58: '\u0d46', 0x59: '\u0d47', 0x5a: '\u0d48',
+		0x5c: '\u0d4a', 0x5d: '\u0d4b', 0x5e: '\u0d4c', 0x5f: '\u0d4d', 0x60: '\u0d57', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u0d60', 0x7c: '\u0d61', 0x7d: '\u0d62', 0x7e: '\u0d63', 0x7f: '\u0d79',
+	},
+	Oriya: {
+		0x00: '\u0b01', 0x01: '\u0b02', 0x02: '\u0b03', 0x03: '\u0b05', 0x04: '\u0b06', 0x05: '\u0b07',
+		0x06: '\u0b08', 0x07: '\u0b09', 0x08: '\u0b0a', 0x09: '\u0b0b', 0x0a: '\n', 0x0b: '\u0b0c',
+		0x0d: '\r', 0x0f: '\u0b0f', 0x10: '\u0b10', 0x13: '\u0b13', 0x14: '\u0b14', 0x15: '\u0b15',
+		0x16: '\u0b16', 0x17: '\u0b17', 0x18: '\u0b18', 0x19: '\u0b19', 0x1a: '\u0b1a', 0x1c: '\u0b1b',
+		0x1d: '\u0b1c', 0x1e: '\u0b1d', 0x1f: '\u0b1e', 0x20: ' ', 0x21: '!', 0x22: '\u0b1f',
+		0x23: '\u0b20', 0x24: '\u0b21', 0x25: '\u0b22', 0x26: '\u0b23', 0x27: '\u0b24', 0x28: ')',
+		0x29: '(', 0x2a: '\u0b25', 0x2b: '\u0b26', 0x2c: ',', 0x2d: '\u0b27', 0x2e: '.',
+		0x2f: '\u0b28', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4',
+		0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':',
+		0x3b: ';', 0x3d: '\u0b2a', 0x3e: '\u0b2b', 0x3f: '?', 0x40: '\u0b2c', 0x41: '\u0b2d',
+		0x42: '\u0b2e', 0x43: '\u0b2f', 0x44: '\u0b30', 0x46: '\u0b32', 0x47: '\u0b33', 0x49: '\u0b35',
+		0x4a: '\u0b36', 0x4b: '\u0b37', 0x4c: '\u0b38', 0x4d: '\u0b39', 0x4e: '\u0b3c', 0x4f: '\u0b3d',
+		0x50: '\u0b3e', 0x51: '\u0b3f', 0x52: '\u0b40', 0x53: '\u0b41', 0x54: '\u0b42', 0x55: '\u0b43',
+		0x56: '\u0b44', 0x59: '\u0b47', 0x5a: '\u0b48', 0x5d: '\u0b4b', 0x5e: '\u0b4c', 0x5f: '\u0b4d',
+		0x60: '\u0b56', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e',
+		0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k',
+		0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q',
+		0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+		0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u0b57', 0x7c: '\u0b60', 0x7d: '\u0b61',
+		0x7e: '\u0b62', 0x7f: '\u0b63',
+	},
+	Punjabi: {
+		0x00: '\u0a01', 0x01: '\u0a02', 0x02: '\u0a03', 0x03: '\u0a05', 0x04: '\u0a06', 0x05: '\u0a07',
+		0x06: '\u0a08', 0x07: '\u0a09', 0x08: '\u0a0a', 0x0a: '\n', 0x0d: '\r', 0x0f: '\u0a0f',
+		0x10: '\u0a10', 0x13: '\u0a13', 0x14: '\u0a14', 0x15: '\u0a15', 0x16: '\u0a16', 0x17: '\u0a17',
+		0x18: '\u0a18', 0x19: '\u0a19', 0x1a: '\u0a1a', 0x1c: '\u0a1b', 0x1d: '\u0a1c', 0x1e: '\u0a1d',
+		0x1f: '\u0a1e', 0x20: ' ', 0x21: '!', 0x22: '\u0a1f', 0x23: '\u0a20', 0x24: '\u0a21',
+		0x25: '\u0a22', 0x26: '\u0a23', 0x27: '\u0a24', 0x28: ')', 0x29: '(', 0x2a: '\u0a25',
+		0x2b: '\u0a26', 0x2c: ',', 0x2d: '\u0a27', 0x2e: '.', 0x2f: '\u0a28', 0x30: '0',
+		0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6',
+		0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: '\u0a2a',
+		0x3e: '\u0a2b', 0x3f: '?', 0x40: '\u0a2c', 0x41: '\u0a2d', 0x42: '\u0a2e', 0x43: '\u0a2f',
+		0x44: '\u0a30', 0x46: '\u0a32', 0x47: '\u0a33', 0x49: '\u0a35', 0x4a: '\u0a36', 0x4c: '\u0a38',
+		0x4d: '\u0a39', 0x4e: '\u0a3c', 0x50: '\u0a3e', 0x51: '\u0a3f', 0x52: '\u0a40', 0x53: '\u0a41',
+		0x54: '\u0a42', 0x59: '\u0a47', 0x5a: '\u0a48', 0x5d: '\u0a4b', 0x5e: '\u0a4c', 0x5f: '\u0a4d',
+		0x60: '\u0a51', 0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e',
+		0x66: 'f', 0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k',
+		0x6c: 'l', 0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q',
+		0x72: 'r', 0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w',
+		0x78: 'x', 0x79: 'y', 0x7a: 'z', 0x7b: '\u0a70', 0x7c: '\u0a71', 0x7d: '\u0a72',
+		0x7e: '\u0a73', 0x7f: '\u0a74',
+	},
+	Tamil: {
+		0x01: '\u0b82', 0x02: '\u0b83', 0x03: '\u0b85', 0x04: '\u0b86', 0x05: '\u0b87', 0x06: '\u0b88',
+		0x07: '\u0b89', 0x08: '\u0b8a', 0x0a: '\n', 0x0d: '\r', 0x0e: '\u0b8e', 0x0f: '\u0b8f',
+		0x10: '\u0b90', 0x12: '\u0b92', 0x13: '\u0b93', 0x14: '\u0b94', 0x15: '\u0b95', 0x19: '\u0b99',
+		0x1a: '\u0b9a', 0x1d: '\u0b9c', 0x1f: '\u0b9e', 0x20: ' ', 0x21: '!', 0x22: '\u0b9f',
+		0x26: '\u0ba3', 0x27: '\u0ba4', 0x28: ')', 0x29: '(', 0x2c: ',', 0x2e: '.',
+		0x2f: '\u0ba8', 0x30: '0', 0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4',
+		0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':',
+		0x3b: ';', 0x3c: '\u0ba9', 0x3d: '\u0baa', 0x3f: '?', 0x42: '\u0bae', 0x43: '\u0baf',
+		0x44: '\u0bb0', 0x45: '\u0bb1', 0x46: '\u0bb2', 0x47: '\u0bb3', 0x48: '\u0bb4', 0x49: '\u0bb5',
+		0x4a: '\u0bb6', 0x4b: '\u0bb7', 0x4c: '\u0bb8', 0x4d: '\u0bb9', 0x50: '\u0bbe', 0x51: '\u0bbf',
+		0x52: '\u0bc0', 0x53: '\u0bc1', 0x54: '\u0bc2', 0x58: '\u0bc6', 0x59: '\u0bc7', 0x5a: '\u0bc8',
+		0x5c: '\u0bca', 0x5d: '\u0bcb', 0x5e: '\u0bcc', 0x5f: '\u0bcd', 0x60: '\u0bd0', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u0bd7', 0x7c: '\u0bf0', 0x7d: '\u0bf1', 0x7e: '\u0bf2', 0x7f: '\u0bf9',
+	},
+	Telugu: {
+		0x00: '\u0c01', 0x01: '\u0c02', 0x02: '\u0c03', 0x03: '\u0c05', 0x04: '\u0c06', 0x05: '\u0c07',
+		0x06: '\u0c08', 0x07: '\u0c09', 0x08: '\u0c0a', 0x09: '\u0c0b', 0x0a: '\n', 0x0b: '\u0c0c',
+		0x0d: '\r', 0x0e: '\u0c0e', 0x0f: '\u0c0f', 0x10: '\u0c10', 0x12: '\u0c12', 0x13: '\u0c13',
+		0x14: '\u0c14', 0x15: '\u0c15', 0x16: '\u0c16', 0x17: '\u0c17', 0x18: '\u0c18', 0x19: '\u0c19',
+		0x1a: '\u0c1a', 0x1c: '\u0c1b', 0x1d: '\u0c1c', 0x1e: '\u0c1d', 0x1f: '\u0c1e', 0x20: ' ',
+		0x21: '!', 0x22: '\u0c1f', 0x23: '\u0c20', 0x24: '\u0c21', 0x25: '\u0c22', 0x26: '\u0c23',
+		0x27: '\u0c24', 0x28: ')', 0x29: '(', 0x2a: '\u0c25', 0x2b: '\u0c26', 0x2c: ',',
+		0x2d: '\u0c27', 0x2e: '.', 0x2f: '\u0c28', 0x30: '0', 0x31: '1', 0x32: '2',
+		0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6', 0x37: '7', 0x38: '8',
+		0x39: '9', 0x3a: ':', 0x3b: ';', 0x3d: '\u0c2a', 0x3e: '\u0c2b', 0x3f: '?',
+		0x40: '\u0c2c', 0x41: '\u0c2d', 0x42: '\u0c2e', 0x43: '\u0c2f', 0x44: '\u0c30', 0x45: '\u0c31',
+		0x46: '\u0c32', 0x47: '\u0c33', 0x49: '\u0c35', 0x4a: '\u0c36', 0x4b: '\u0c37', 0x4c: '\u0c38',
+		0x4d: '\u0c39', 0x4f: '\u0c3d', 0x50: '\u0c3e', 0x51: '\u0c3f', 0x52: '\u0c40', 0x53: '\u0c41',
+		0x54: '\u0c42', 0x55: '\u0c43', 0x56: '\u0c44', 0x58: '\u0c46', 0x59: '\u0c47', 0x5a: '\u0c48',
+		0x5c: '\u0c4a', 0x5d: '\u0c4b', 0x5e: '\u0c4c', 0x5f: '\u0c4d', 0x60: '\u0c55', 0x61: 'a',
+		0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f', 0x67: 'g',
+		0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l', 0x6d: 'm',
+		0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r', 0x73: 's',
+		0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x', 0x79: 'y',
+		0x7a: 'z', 0x7b: '\u0c56', 0x7c: '\u0c60', 0x7d: '\u0c61', 0x7e: '\u0c62', 0x7f: '\u0c63',
+	},
+	Urdu: {
+		0x00: '\u0627', 0x01: '\u0622', 0x02: '\u0628', 0x03: '\u067b', 0x04: '\u0680', 0x05: '\u067e',
+		0x06: '\u06a6', 0x07: '\u062a', 0x08: '\u06c2', 0x09: '\u067f', 0x0a: '\n', 0x0b: '\u0679',
+		0x0c: '\u067d', 0x0d: '\r', 0x0e: '\u067a', 0x0f: '\u067c', 0x10: '\u062b', 0x11: '\u062c',
+		0x12: '\u0681', 0x13: '\u0684', 0x14: '\u0683', 0x15: '\u0685', 0x16: '\u0686', 0x17: '\u0687',
+		0x18: '\u062d', 0x19: '\u062e', 0x1a: '\u062f', 0x1c: '\u068c', 0x1d: '\u0688', 0x1e: '\u0689',
+		0x1f: '\u068a', 0x20: ' ', 0x21: '!', 0x22: '\u068f', 0x23: '\u068d', 0x24: '\u0630',
+		0x25: '\u0631', 0x26: '\u0691', 0x27: '\u0693', 0x28: ')', 0x29: '(', 0x2a: '\u0699',
+		0x2b: '\u0632', 0x2c: ',', 0x2d: '\u0696', 0x2e: '.', 0x2f: '\u0698', 0x30: '0',
+		0x31: '1', 0x32: '2', 0x33: '3', 0x34: '4', 0x35: '5', 0x36: '6',
+		0x37: '7', 0x38: '8', 0x39: '9', 0x3a: ':', 0x3b: ';', 0x3c: '\u069a',
+		0x3d: '\u0633', 0x3e: '\u0634', 0x3f: '?', 0x40: '\u0635', 0x41: '\u0636', 0x42: '\u0637',
+		0x43: '\u0638', 0x44: '\u0639', 0x45: '\u0641', 0x46: '\u0642', 0x47: '\u06a9', 0x48: '\u06aa',
+		0x49: '\u06ab', 0x4a: '\u06af', 0x4b: '\u06b3', 0x4c: '\u06b1', 0x4d: '\u0644', 0x4e: '\u0645',
+		0x4f: '\u0646', 0x50: '\u06ba', 0x51: '\u06bb', 0x52: '\u06bc', 0x53: '\u0648', 0x54: '\u06c4',
+		0x55: '\u06d5', 0x56: '\u06c1', 0x57: '\u06be', 0x58: '\u0621', 0x59: '\u06cc', 0x5a: '\u06d0',
+		0x5b: '\u06d2', 0x5c: '\u064d', 0x5d: '\u0650', 0x5e: '\u064f', 0x5f: '\u0657', 0x60: '\u0654',
+		0x61: 'a', 0x62: 'b', 0x63: 'c', 0x64: 'd', 0x65: 'e', 0x66: 'f',
+		0x67: 'g', 0x68: 'h', 0x69: 'i', 0x6a: 'j', 0x6b: 'k', 0x6c: 'l',
+		0x6d: 'm', 0x6e: 'n', 0x6f: 'o', 0x70: 'p', 0x71: 'q', 0x72: 'r',
+		0x73: 's', 0x74: 't', 0x75: 'u', 0x76: 'v', 0x77: 'w', 0x78: 'x',
+		0x79: 'y', 0x7a: 'z', 0x7b: '\u0655', 0x7c: '\u0651', 0x7d: '\u0653', 0x7e: '\u0656',
+		0x7f: '\u0670',
+	},
+}
+
+var singleShift = map[Language]map[byte]rune{
+	Turkish: {
+		0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x47: 'Ğ', 0x49: 'İ', 0x53: 'Ş', 0x63: 'ç', 0x65: '€', 0x67: 'ğ', 0x69: 'ı',
+		0x73: 'ş',
+	},
+	Spanish: {
+		0x09: 'ç', 0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~',
+		0x3e: ']', 0x40: '|', 0x41: 'Á', 0x49: 'Í', 0x4f: 'Ó', 0x55: 'Ú', 0x61: 'á', 0x65: '€',
+		0x69: 'í', 0x6f: 'ó', 0x75: 'ú',
+	},
+	Portuguese: {
+		0x05: 'ê', 0x09: 'ç', 0x0a: '\f', 0x0b: 'Ô', 0x0c: 'ô', 0x0e: 'Á', 0x0f: 'á', 0x12: 'Φ',
+		0x13: 'Γ', 0x14: '^', 0x15: 'Ω', 0x16: 'Π', 0x17: 'Ψ', 0x18: 'Σ', 0x19: 'Θ', 0x1f: 'Ê',
+		0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'À',
+		0x49: 'Í', 0x4f: 'Ó', 0x55: 'Ú', 0x5b: 'Ã', 0x5c: 'Õ', 0x61: 'Â', 0x65: '€', 0x69: 'í',
+		0x6f: 'ó', 0x75: 'ú', 0x7b: 'ã', 0x7c: 'õ', 0x7f: 'â',
+	},
+	Bengali: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u09e6', 0x1a: '\u09e7', 0x1c: '\u09e8', 0x1d: '\u09e9', 0x1e: '\u09ea', 0x1f: '\u09eb',
+		0x20: '\u09ec', 0x21: '\u09ed', 0x22: '\u09ee', 0x23: '\u09ef', 0x24: '\u09df', 0x25: '\u09e0',
+		0x26: '\u09e1', 0x27: '\u09e2', 0x28: '{', 0x29: '}', 0x2a: '\u09e3', 0x2b: '\u09f2',
+		0x2c: '\u09f3', 0x2d: '\u09f4', 0x2e: '\u09f5', 0x2f: '\\', 0x30: '\u09f6', 0x31: '\u09f7',
+		0x32: '\u09f8', 0x33: '\u09f9', 0x34: '\u09fa', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+		0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+		0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+		0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+		0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Gujarati: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0ae6', 0x1d: '\u0ae7', 0x1e: '\u0ae8', 0x1f: '\u0ae9',
+		0x20: '\u0aea', 0x21: '\u0aeb', 0x22: '\u0aec', 0x23: '\u0aed', 0x24: '\u0aee', 0x25: '\u0aef',
+		0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+		0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+		0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+		0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+		0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Hindi: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0966', 0x1d: '\u0967', 0x1e: '\u0968', 0x1f: '\u0969',
+		0x20: '\u096a', 0x21: '\u096b', 0x22: '\u096c', 0x23: '\u096d', 0x24: '\u096e', 0x25: '\u096f',
+		0x26: '\u0951', 0x27: '\u0952', 0x28: '{', 0x29: '}', 0x2a: '\u0953', 0x2b: '\u0954',
+		0x2c: '\u0958', 0x2d: '\u0959', 0x2e: '\u095a', 0x2f: '\\', 0x30: '\u095b', 0x31: '\u095c',
+		0x32: '\u095d', 0x33: '\u095e', 0x34: '\u095f', 0x35: '\u0960', 0x36: '\u0961', 0x37: '\u0962',
+		0x38: '\u0963', 0x39: '\u0970', 0x3a: '\u0971', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+		0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+		0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+		0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+		0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Kannada: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0ce6', 0x1d: '\u0ce7', 0x1e: '\u0ce8', 0x1f: '\u0ce9',
+		0x20: '\u0cea', 0x21: '\u0ceb', 0x22: '\u0cec', 0x23: '\u0ced', 0x24: '\u0cee', 0x25: '\u0cef',
+		0x26: '\u0cde', 0x27: '\u0cf1', 0x28: '{', 0x29: '}', 0x2a: '\u0cf2', 0x2f: '\\',
+		0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B',
+		0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H',
+		0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N',
+		0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T',
+		0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z',
+		0x65: '€',
+	},
+	Malayalam: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0d66', 0x1d: '\u0d67', 0x1e: '\u0d68', 0x1f: '\u0d69',
+		0x20: '\u0d6a', 0x21: '\u0d6b', 0x22: '\u0d6c', 0x23: '\u0d6d', 0x24: '\u0d6e', 0x25: '\u0d6f',
+		0x26: '\u0d70', 0x27: '\u0d71', 0x28: '{', 0x29: '}', 0x2a: '\u0d72', 0x2b: '\u0d73',
+		0x2c: '\u0d74', 0x2d: '\u0d75', 0x2e: '\u0d7a', 0x2f: '\\', 0x30: '\u0d7b', 0x31: '\u0d7c',
+		0x32: '\u0d7d', 0x33: '\u0d7e', 0x34: '\u0d7f', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+		0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+		0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+		0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+		0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Oriya: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0b66', 0x1d: '\u0b67', 0x1e: '\u0b68', 0x1f: '\u0b69',
+		0x20: '\u0b6a', 0x21: '\u0b6b', 0x22: '\u0b6c', 0x23: '\u0b6d', 0x24: '\u0b6e', 0x25: '\u0b6f',
+		0x26: '\u0b5c', 0x27: '\u0b5d', 0x28: '{', 0x29: '}', 0x2a: '\u0b5f', 0x2b: '\u0b70',
+		0x2c: '\u0b71', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|',
+		0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E', 0x46: 'F',
+		0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K', 0x4c: 'L',
+		0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q', 0x52: 'R',
+		0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W', 0x58: 'X',
+		0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Punjabi: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0a66', 0x1d: '\u0a67', 0x1e: '\u0a68', 0x1f: '\u0a69',
+		0x20: '\u0a6a', 0x21: '\u0a6b', 0x22: '\u0a6c', 0x23: '\u0a6d', 0x24: '\u0a6e', 0x25: '\u0a6f',
+		0x26: '\u0a59', 0x27: '\u0a5a', 0x28: '{', 0x29: '}', 0x2a: '\u0a5b', 0x2b: '\u0a5c',
+		0x2c: '\u0a5e', 0x2d: '\u0a75', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D', 0x45: 'E',
+		0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J', 0x4b: 'K',
+		0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P', 0x51: 'Q',
+		0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V', 0x57: 'W',
+		0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Tamil: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0964', 0x1a: '\u0965', 0x1c: '\u0be6', 0x1d: '\u0be7', 0x1e: '\u0be8', 0x1f: '\u0be9',
+		0x20: '\u0bea', 0x21: '\u0beb', 0x22: '\u0bec', 0x23: '\u0bed', 0x24: '\u0bee', 0x25: '\u0bef',
+		0x26: '\u0bf3', 0x27: '\u0bf4', 0x28: '{', 0x29: '}', 0x2a: '\u0bf5', 0x2b: '\u0bf6',
+		0x2c: '\u0bf7', 0x2d: '\u0bf8', 0x2e: '\u0bfa', 0x2f: '\\', 0x3c: '[', 0x3d: '~',
+		0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C', 0x44: 'D',
+		0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I', 0x4a: 'J',
+		0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O', 0x50: 'P',
+		0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U', 0x56: 'V',
+		0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Telugu: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x1c: '\u0c66', 0x1d: '\u0c67', 0x1e: '\u0c68', 0x1f: '\u0c69', 0x20: '\u0c6a', 0x21: '\u0c6b',
+		0x22: '\u0c6c', 0x23: '\u0c6d', 0x24: '\u0c6e', 0x25: '\u0c6f', 0x26: '\u0c58', 0x27: '\u0c59',
+		0x28: '{', 0x29: '}', 0x2a: '\u0c78', 0x2b: '\u0c79', 0x2c: '\u0c7a', 0x2d: '\u0c7b',
+		0x2e: '\u0c7c', 0x2f: '\\', 0x30: '\u0c7d', 0x31: '\u0c7e', 0x32: '\u0c7f', 0x3c: '[',
+		0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C',
+		0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I',
+		0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O',
+		0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U',
+		0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+	Urdu: {
+		0x00: '@', 0x01: '£', 0x02: '$', 0x03: '¥', 0x04: '¿', 0x05: '"',
+		0x06: '¤', 0x07: '%', 0x08: '&', 0x09: '\'', 0x0a: '\f', 0x0b: '*',
+		0x0c: '+', 0x0e: '-', 0x0f: '/', 0x10: '<', 0x11: '=', 0x12: '>',
+		0x13: '¡', 0x14: '^', 0x15: '¡', 0x16: '_', 0x17: '#', 0x18: '*',
+		0x19: '\u0600', 0x1a: '\u0601', 0x1c: '\u06f0', 0x1d: '\u06f1', 0x1e: '\u06f2', 0x1f: '\u06f3',
+		0x20: '\u06f4', 0x21: '\u06f5', 0x22: '\u06f6', 0x23: '\u06f7', 0x24: '\u06f8', 0x25: '\u06f9',
+		0x26: '\u060c', 0x27: '\u060d', 0x28: '{', 0x29: '}', 0x2a: '\u060e', 0x2b: '\u060f',
+		0x2c: '\u0610', 0x2d: '\u0611', 0x2e: '\u0612', 0x2f: '\\', 0x30: '\u0613', 0x31: '\u0614',
+		0x32: '\u061b', 0x33: '\u061f', 0x34: '\u0640', 0x35: '\u0652', 0x36: '\u0658', 0x37: '\u066b',
+		0x38: '\u066c', 0x39: '\u0672', 0x3a: '\u0673', 0x3b: '\u06cd', 0x3c: '[', 0x3d: '~',
+		0x3e: ']', 0x3f: '\u06d4', 0x40: '|', 0x41: 'A', 0x42: 'B', 0x43: 'C',
+		0x44: 'D', 0x45: 'E', 0x46: 'F', 0x47: 'G', 0x48: 'H', 0x49: 'I',
+		0x4a: 'J', 0x4b: 'K', 0x4c: 'L', 0x4d: 'M', 0x4e: 'N', 0x4f: 'O',
+		0x50: 'P', 0x51: 'Q', 0x52: 'R', 0x53: 'S', 0x54: 'T', 0x55: 'U',
+		0x56: 'V', 0x57: 'W', 0x58: 'X', 0x59: 'Y', 0x5a: 'Z', 0x65: '€',
+	},
+}
+
+// languageTables caches the tables built by tablesFor.
+var languageTables = struct {
+	sync.Mutex
+	m map[[2]Language]*gsm7Tables
+}{m: make(map[[2]Language]*gsm7Tables)}
+
+// tablesFor returns the lookup tables of the given locking shift and
+// single shift languages. Unsupported languages fall back to the
+// default alphabet and extension table.
+func tablesFor(locking, single Language) *gsm7Tables {
+	if lockingShift[locking] == nil && lockingAlphabet[locking] == nil && singleShift[single] == nil {
+		return defaultTables
+	}
+	key := [2]Language{locking, single}
+	languageTables.Lock()
+	defer languageTables.Unlock()
+	if t, ok := languageTables.m[key]; ok {
+		return t
+	}
+	t := &gsm7Tables{
+		forwardEscape: forwardEscape,
+		reverse:       lockingAlphabet[locking],
+		reverseEscape: reverseEscape,
+	}
+	if t.reverse == nil {
+		t.reverse = make(map[byte]rune)
+		for b, r := range reverseLookup {
+			t.reverse[b] = r
+		}
+		for b, r := range lockingShift[locking] {
+			t.reverse[b] = r
+		}
+	}
+	t.forward = forwardTable(t.reverse)
+	if m := singleShift[single]; m != nil {
+		t.reverseEscape = m
+		t.forwardEscape = forwardTable(m)
+	}
+	languageTables.m[key] = t
+	return t
+}
+
+// forwardTable returns the reverse of the given table. Characters listed
+// more than once, like '*' in the Indian single shift tables, are encoded
+// with the lowest code.
+func forwardTable(m map[byte]rune) map[rune]byte {
+	f := make(map[rune]byte, len(m))
+	for b, r := range m {
+		if v, ok := f[r]; !ok || b < v {
+			f[r] = b
+		}
+	}
+	return f
+}
+
+// GSM7Language returns a GSM 7-bit Encoding with the given national
+// language locking shift and single shift tables. Use DefaultLanguage
+// for the default alphabet or extension table.
+//
+// Set the packed flag to true if you wish to convert septets to octets,
+// this should be false for most SMPP providers.
+func GSM7Language(packed bool, locking, single Language) encoding.Encoding {
+	return gsm7Encoding{packed: packed, tables: tablesFor(locking, single)}
+}
+
+// ValidateGSM7Language returns the characters, in the given text, that
+// can not be represented in GSM 7-bit encoding with the given national
+// language locking shift and single shift tables.
+func ValidateGSM7Language(text string, locking, single Language) []rune {
+	t := tablesFor(locking, single)
+	invalidChars := make([]rune, 0, 4)
+	for _, r := range text {
+		if _, ok := t.forward[r]; !ok {
+			if _, ok := t.forwardEscape[r]; !ok {
+				invalidChars = append(invalidChars, r)
+			}
+		}
+	}
+	return invalidChars
+}
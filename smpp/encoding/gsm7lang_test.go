@@ -0,0 +1,117 @@
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+var languageTests = []struct {
+	Locking Language
+	Single  Language
+	Text    string
+	Buff    []byte
+}{
+	{Locking: Turkish, Text: "ğışİ€", Buff: []byte{0x0c, 0x07, 0x1d, 0x40, 0x04}},
+	{Single: Turkish, Text: "ğış", Buff: []byte{0x1b, 0x67, 0x1b, 0x69, 0x1b, 0x73}},
+	{Locking: Turkish, Single: Turkish, Text: "ğ{", Buff: []byte{0x0c, 0x1b, 0x28}},
+	{Single: Spanish, Text: "áÚç", Buff: []byte{0x1b, 0x61, 0x1b, 0x55, 0x1b, 0x09}},
+	{Locking: Portuguese, Text: "ãçÊ", Buff: []byte{0x7b, 0x09, 0x1e}},
+	{Locking: Portuguese, Single: Portuguese, Text: "ãÂ", Buff: []byte{0x7b, 0x1c}},
+	{Single: Portuguese, Text: "Õa", Buff: []byte{0x1b, 0x5c, 0x61}},
+	{Locking: Portuguese, Text: "º", Buff: []byte{0x24}},
+	{Locking: Hindi, Text: "नमस्ते 1", Buff: []byte{0x2f, 0x42, 0x4c, 0x5f, 0x27, 0x59, 0x20, 0x31}},
+	{Locking: Hindi, Single: Hindi, Text: "A१", Buff: []byte{0x1b, 0x41, 0x1b, 0x1d}},
+	{Locking: Urdu, Text: "سلام", Buff: []byte{0x3d, 0x4d, 0x00, 0x4e}},
+	{Single: Tamil, Text: "*€", Buff: []byte{0x2a, 0x1b, 0x65}},
+	{Locking: Tamil, Single: Tamil, Text: "*௧", Buff: []byte{0x1b, 0x0b, 0x1b, 0x1d}},
+	{Locking: Language(99), Single: Language(99), Text: "a€", Buff: []byte{0x61, 0x1b, 0x65}},
+}
+
+func TestGSM7LanguageTables(t *testing.T) {
+	for l := Bengali; l <= Urdu; l++ {
+		alphabet, ext := lockingAlphabet[l], singleShift[l]
+		if alphabet == nil || ext == nil {
+			t.Fatalf("%s: missing tables", l)
+		}
+		// The Indian tables share the layout of the control characters,
+		// digits and Latin letters.
+		for b, r := range map[byte]rune{0x0a: '\n', 0x0d: '\r', 0x20: ' ', 0x30: '0', 0x61: 'a', 0x7a: 'z'} {
+			if alphabet[b] != r {
+				t.Fatalf("%s: unexpected locking shift %#x: want %q, have %q", l, b, r, alphabet[b])
+			}
+		}
+		for b, r := range map[byte]rune{0x0a: '\f', 0x28: '{', 0x2f: '\\', 0x3e: ']', 0x40: '|', 0x41: 'A', 0x5a: 'Z', 0x65: '€'} {
+			if ext[b] != r {
+				t.Fatalf("%s: unexpected single shift %#x: want %q, have %q", l, b, r, ext[b])
+			}
+		}
+		if _, ok := alphabet[escapeSequence]; ok {
+			t.Fatalf("%s: escape in locking shift table", l)
+		}
+	}
+	for l := Turkish; l <= Urdu; l++ {
+		tables := tablesFor(l, l)
+		for b, r := range tables.reverse {
+			if v := tables.forward[r]; v != b {
+				t.Fatalf("%s: %q does not round trip: want %#x, have %#x", l, r, b, v)
+			}
+		}
+		for b, r := range tables.reverseEscape {
+			if v := tables.forwardEscape[r]; v != b && tables.reverseEscape[v] != r {
+				t.Fatalf("%s: %q does not round trip: want %#x, have %#x", l, r, b, v)
+			}
+		}
+	}
+}
+
+func TestGSM7LanguageUndefined(t *testing.T) {
+	d := GSM7Language(false, Bengali, Bengali).NewDecoder()
+	for _, b := range [][]byte{{0x3c}, {0x1b, 0x3f}} {
+		if _, _, err := transform.Bytes(d, b); err != ErrInvalidByte {
+			t.Fatalf("unexpected error decoding %x: want %v, have %v", b, ErrInvalidByte, err)
+		}
+	}
+}
+
+func TestGSM7LanguageEncoder(t *testing.T) {
+	for _, tc := range languageTests {
+		e := GSM7Language(false, tc.Locking, tc.Single).NewEncoder()
+		have, _, err := transform.Bytes(e, []byte(tc.Text))
+		if err != nil {
+			t.Fatalf("%s/%s: unexpected error encoding %q: %v", tc.Locking, tc.Single, tc.Text, err)
+		}
+		if !bytes.Equal(have, tc.Buff) {
+			t.Fatalf("%s/%s: unexpected encoding of %q: want %x, have %x",
+				tc.Locking, tc.Single, tc.Text, tc.Buff, have)
+		}
+	}
+}
+
+func TestGSM7LanguageDecoder(t *testing.T) {
+	for _, tc := range languageTests {
+		d := GSM7Language(false, tc.Locking, tc.Single).NewDecoder()
+		have, _, err := transform.Bytes(d, tc.Buff)
+		if err != nil {
+			t.Fatalf("%s/%s: unexpected error decoding %x: %v", tc.Locking, tc.Single, tc.Buff, err)
+		}
+		if string(have) != tc.Text {
+			t.Fatalf("%s/%s: unexpected decoding of %x: want %q, have %q",
+				tc.Locking, tc.Single, tc.Buff, tc.Text, have)
+		}
+	}
+}
+
+func TestValidateGSM7Language(t *testing.T) {
+	text := "Türkçe ğ ş ı"
+	if invalid := ValidateGSM7String(text); len(invalid) != 4 {
+		t.Fatalf("unexpected invalid characters with default alphabet: %q", invalid)
+	}
+	if invalid := ValidateGSM7Language(text, Turkish, Turkish); len(invalid) != 0 {
+		t.Fatalf("unexpected invalid characters with Turkish tables: %q", invalid)
+	}
+	if invalid := ValidateGSM7Language(text, Turkish, DefaultLanguage); len(invalid) != 0 {
+		t.Fatalf("unexpected invalid characters with Turkish locking shift: %q", invalid)
+	}
+}
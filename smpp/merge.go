@@ -0,0 +1,381 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/smpp/pdu/pduudh"
+)
+
+// Prefixes of the Receiver keys in its Store: the parts of long
+// messages being merged, and the long messages recently merged.
+const (
+	mergePrefix     = "merge/"
+	mergeDonePrefix = "merged/"
+)
+
+// userData returns the information elements of the user data header
+// and the data of the short message in p.
+//
+// The header is taken from the GSMUserData field when decoded, or
+// parsed from the short message if the UDHI flag of the esm_class is
+// set.
+func userData(p pdu.Body) (pduudh.UDH, []byte) {
+	f := p.Fields()
+	sm, ok := f[pdufield.ShortMessage].(*pdufield.SM)
+	if !ok {
+		return nil, nil
+	}
+	if l, ok := f[pdufield.GSMUserData].(*pdufield.UDHList); ok {
+		udh := make(pduudh.UDH, 0, len(l.Data))
+		for _, u := range l.Data {
+			id, data := pduudh.IEI(u.IEI.Data), u.IEData.Data
+			ie, err := pduudh.NewIE(id, data)
+			if err != nil {
+				ie = pduudh.Raw{ID: id, Content: data}
+			}
+			udh = append(udh, ie)
+		}
+		return udh, sm.Data
+	}
+	esm := f[pdufield.ESMClass]
+	if esm == nil || len(esm.Bytes()) == 0 || esm.Bytes()[0]&0x40 == 0 {
+		return nil, sm.Data
+	}
+	udh, data, err := pduudh.Unmarshal(sm.Data)
+	if err != nil {
+		return nil, sm.Data
+	}
+	return udh, data
+}
+
+// concat contains the concatenation info of a part of a long message.
+type concat struct {
+	ref   int // reference number
+	total int // total number of parts
+	seq   int // part number, starting at 1
+}
+
+// concatInfo returns the concatenation info of p, taken from the user
+// data header IEIs 0x00 (8-bit reference) or 0x08 (16-bit reference),
+// or from the sar_msg_ref_num, sar_total_segments and sar_segment_seqnum
+// TLVs. It returns false if p is not part of a long message.
+func concatInfo(p pdu.Body, udh pduudh.UDH) (concat, bool) {
+	for _, ie := range udh {
+		switch ie := ie.(type) {
+		case pduudh.Concat8:
+			return concat{ref: int(ie.Ref), total: int(ie.Total), seq: int(ie.Seq)}, true
+		case pduudh.Concat16:
+			return concat{ref: int(ie.Ref), total: int(ie.Total), seq: int(ie.Seq)}, true
+		}
+	}
+	tlv := p.TLVFields()
+	ref, err := tlv.GetUint16(pdutlv.TagSarMsgRefNum)
+	if err != nil {
+		return concat{}, false
+	}
+	total, err := tlv.GetUint8(pdutlv.TagSarTotalSegments)
+	if err != nil {
+		return concat{}, false
+	}
+	seq, err := tlv.GetUint8(pdutlv.TagSarSegmentSeqnum)
+	if err != nil {
+		return concat{}, false
+	}
+	return concat{ref: int(ref), total: int(total), seq: int(seq)}, true
+}
+
+// mergeMsgPrefix returns the Store key prefix of the parts of a long
+// message, identified by its source and destination addresses, the
+// reference number and the number of parts. The addresses are escaped,
+// so they can't contain the separators of the key.
+func mergeMsgPrefix(p pdu.Body, c concat) string {
+	var src, dst string
+	f := p.Fields()
+	if v := f[pdufield.SourceAddr]; v != nil {
+		src = v.String()
+	}
+	if v := f[pdufield.DestinationAddr]; v != nil {
+		dst = v.String()
+	}
+	return fmt.Sprintf("%s%s|%s|%d|%d/", mergePrefix,
+		url.QueryEscape(src), url.QueryEscape(dst), c.ref, c.total)
+}
+
+// MergedMessage is a long message merged by a Receiver from its parts.
+type MergedMessage struct {
+	Src        string
+	Dst        string
+	DataCoding pdutext.DataCoding
+	Text       string         // Text of all the parts, decoded according to DataCoding.
+	Data       []byte         // Data of all the parts, without user data header.
+	UDH        []pdufield.UDH // User data header elements of the parts, except concatenation.
+	Parts      []pdu.Body     // Original PDUs of the parts, in order.
+}
+
+// MergedHandlerFunc is the handler function that a Receiver calls
+// when all the parts of a long message arrive.
+type MergedHandlerFunc func(m *MergedMessage)
+
+// newMergedMessage returns a new MergedMessage with the given parts.
+func newMergedMessage(parts []pdu.Body) *MergedMessage {
+	m := &MergedMessage{Parts: parts}
+	f := parts[0].Fields()
+	if v := f[pdufield.SourceAddr]; v != nil {
+		m.Src = v.String()
+	}
+	if v := f[pdufield.DestinationAddr]; v != nil {
+		m.Dst = v.String()
+	}
+	if v := f[pdufield.DataCoding]; v != nil && len(v.Bytes()) > 0 {
+		m.DataCoding = pdutext.DataCoding(v.Bytes()[0])
+	}
+	for _, p := range parts {
+		udh, data := userData(p)
+		m.Data = append(m.Data, data...)
+	udh:
+		for _, ie := range udh {
+			iei, data := uint8(ie.IEI()), ie.Data()
+			if ie.IEI() == pduudh.IEIConcat8 || ie.IEI() == pduudh.IEIConcat16 {
+				continue
+			}
+			for _, u := range m.UDH {
+				if u.IEI.Data == iei && bytes.Equal(u.IEData.Data, data) {
+					continue udh // repeated in every part
+				}
+			}
+			m.UDH = append(m.UDH, pdufield.UDH{
+				IEI:      pdufield.Fixed{Data: iei},
+				IELength: pdufield.Fixed{Data: uint8(len(data))},
+				IEData:   pdufield.Variable{Data: data},
+			})
+		}
+	}
+	m.Text = decodeText(m.DataCoding, m.Data, m.UDH)
+	return m
+}
+
+// decodeText decodes data according to the data_coding c, and the
+// national language shift tables of GSM 7-bit text in udh. It returns
+// an empty string if the text can't be decoded, as compressed text.
+func decodeText(c pdutext.DataCoding, data []byte, udh []pdufield.UDH) string {
+	var locking, single encoding.Language
+	for _, ie := range udh {
+		if len(ie.IEData.Data) != 1 {
+			continue
+		}
+		switch ie.IEI.Data {
+		case 0x24:
+			single = encoding.Language(ie.IEData.Data[0])
+		case 0x25:
+			locking = encoding.Language(ie.IEData.Data[0])
+		}
+	}
+	text, _ := pdutext.DecodeSMLanguage(c, data, locking, single)
+	return text
+}
+
+// body returns the first part of the message, with the short message
+// set to the data of all the parts and without user data header.
+func (m *MergedMessage) body() pdu.Body {
+	p := m.Parts[0]
+	f := p.Fields()
+	f.Set(pdufield.ShortMessage, m.Data)
+	delete(f, pdufield.UDHLength)
+	delete(f, pdufield.GSMUserData)
+	if esm := f[pdufield.ESMClass]; esm != nil && len(esm.Bytes()) > 0 {
+		f.Set(pdufield.ESMClass, esm.Bytes()[0]&^0x40)
+	}
+	return p
+}
+
+// merge adds p to the parts of its long message. It returns the merged
+// message once all the parts are received, or nil if parts are missing
+// or p is a retransmission of a part already received. It returns false
+// if p is not a valid part of a long message.
+func (r *Receiver) merge(p pdu.Body) (*MergedMessage, bool) {
+	udh, _ := userData(p)
+	c, ok := concatInfo(p, udh)
+	if !ok || c.seq < 1 || c.seq > c.total {
+		return nil, false
+	}
+	parts, err := r.mergePart(mergeMsgPrefix(p, c), c.seq, c.total, p)
+	if err != nil || parts == nil {
+		return nil, true
+	}
+	return newMergedMessage(parts), true
+}
+
+// mergePart adds part number seq of a long message to the store. Once
+// all the parts are stored, it removes them and returns them in order.
+// Parts already stored, or received again after the message was merged,
+// are ignored.
+//
+// Each part is stored as the time it was received followed by the PDU.
+// The parts of merged messages are remembered under mergeDonePrefix
+// until they expire, as the time followed by the hash of their data,
+// to detect late retransmissions. A part with different data is the
+// start of a new message that reuses the reference.
+func (r *Receiver) mergePart(prefix string, seq, total int, p pdu.Body) ([]pdu.Body, error) {
+	r.mg.Lock()
+	defer r.mg.Unlock()
+	done := mergeDonePrefix + strings.TrimPrefix(prefix, mergePrefix)
+	v, err := r.mg.store.Get(fmt.Sprintf("%s%03d", done, seq))
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		if len(v) > 8 && bytes.Equal(v[8:], mergeHash(p)) {
+			return nil, nil
+		}
+		err = r.mg.store.Scan(done, func(k string, v []byte) error {
+			return r.mg.store.Delete(k)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	key := fmt.Sprintf("%s%03d", prefix, seq)
+	if v, err := r.mg.store.Get(key); err != nil || v != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Write(mergeTime(time.Now()))
+	if err := p.SerializeTo(&b); err != nil {
+		return nil, err
+	}
+	if err := r.mg.store.Put(key, b.Bytes()); err != nil {
+		return nil, err
+	}
+	var keys []string
+	r.mg.store.Scan(prefix, func(k string, v []byte) error {
+		keys = append(keys, k)
+		return nil
+	})
+	if len(keys) != total {
+		return nil, nil
+	}
+	parts, err := r.mergeParts(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) != total {
+		return nil, errors.New("corrupted message parts")
+	}
+	now := time.Now()
+	for i, p := range parts {
+		v := append(mergeTime(now), mergeHash(p)...)
+		if err := r.mg.store.Put(fmt.Sprintf("%s%03d", done, i+1), v); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// mergeHash returns the hash of the user data of the part p.
+func mergeHash(p pdu.Body) []byte {
+	_, data := userData(p)
+	h := sha1.Sum(data)
+	return h[:]
+}
+
+// mergeParts removes the parts of a long message from the store and
+// returns them in order. Parts that can't be decoded are discarded.
+// It must be called with r.mg locked.
+func (r *Receiver) mergeParts(prefix string) ([]pdu.Body, error) {
+	var parts []pdu.Body
+	err := r.mg.store.Scan(prefix, func(k string, v []byte) error {
+		if len(v) > 8 {
+			if p, _, _, err := pdu.Decode(bytes.NewReader(v[8:])); err == nil {
+				parts = append(parts, p)
+			}
+		}
+		return r.mg.store.Delete(k)
+	})
+	return parts, err
+}
+
+// mergeTime encodes t as stored with the message parts.
+func mergeTime(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano()))
+	return b
+}
+
+// mergeExpired returns true if the time stored in v is older than the
+// MergeInterval.
+func (r *Receiver) mergeExpired(v []byte) bool {
+	if len(v) < 8 {
+		return true
+	}
+	t := time.Unix(0, int64(binary.BigEndian.Uint64(v)))
+	return time.Since(t) > r.MergeInterval
+}
+
+func (r *Receiver) mergeCleaner() {
+	ticker := time.NewTicker(r.MergeCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, parts := range r.mergeExpire() {
+				if r.IncompleteHandler != nil {
+					r.IncompleteHandler(parts)
+				}
+			}
+
+		case <-r.chanClose:
+			return
+		}
+	}
+}
+
+// mergeExpire removes the long messages that were not completed within
+// the MergeInterval since their last part, and returns their parts.
+func (r *Receiver) mergeExpire() [][]pdu.Body {
+	r.mg.Lock()
+	defer r.mg.Unlock()
+	// A message expires when its most recent part does.
+	fresh := make(map[string]bool)
+	var msgs []string
+	r.mg.store.Scan(mergePrefix, func(k string, v []byte) error {
+		msg := k[:strings.LastIndex(k, "/")+1]
+		if _, ok := fresh[msg]; !ok {
+			msgs = append(msgs, msg)
+		}
+		fresh[msg] = fresh[msg] || !r.mergeExpired(v)
+		return nil
+	})
+	var expired [][]pdu.Body
+	for _, msg := range msgs {
+		if fresh[msg] {
+			continue
+		}
+		if parts, err := r.mergeParts(msg); err == nil && len(parts) > 0 {
+			expired = append(expired, parts)
+		}
+	}
+	r.mg.store.Scan(mergeDonePrefix, func(k string, v []byte) error {
+		if r.mergeExpired(v) {
+			return r.mg.store.Delete(k)
+		}
+		return nil
+	})
+	return expired
+}
@@ -0,0 +1,118 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+)
+
+// DefaultOutbindTimeout is the time to wait for the Outbind PDU after
+// accepting a connection, when OutbindTimeout is not set.
+const DefaultOutbindTimeout = 10 * time.Second
+
+// outbindListener accepts SMSC initiated sessions for Listen.
+type outbindListener struct {
+	l       net.Listener
+	tls     *tls.Config
+	user    string
+	passwd  string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	fd     net.Conn // connection waiting for the Outbind
+	closed bool
+}
+
+func newOutbindListener(l net.Listener, conf *tls.Config, user, passwd string, timeout time.Duration) *outbindListener {
+	if timeout == 0 {
+		timeout = DefaultOutbindTimeout
+	}
+	return &outbindListener{
+		l:       l,
+		tls:     conf,
+		user:    user,
+		passwd:  passwd,
+		timeout: timeout,
+	}
+}
+
+// accept waits for a new connection with a valid Outbind PDU.
+// Connections that fail to send a valid Outbind within the timeout
+// are closed, and the next connection is accepted right away.
+func (o *outbindListener) accept() (Conn, error) {
+	for {
+		fd, err := o.l.Accept()
+		if err != nil {
+			return nil, err
+		}
+		o.mu.Lock()
+		if o.closed {
+			o.mu.Unlock()
+			fd.Close()
+			return nil, errors.New("outbind listener closed")
+		}
+		o.fd = fd
+		o.mu.Unlock()
+		c, err := o.outbind(fd)
+		o.mu.Lock()
+		o.fd = nil
+		o.mu.Unlock()
+		if err == nil {
+			return c, nil
+		}
+	}
+}
+
+// outbind validates the Outbind PDU sent by the SMSC on fd.
+func (o *outbindListener) outbind(fd net.Conn) (Conn, error) {
+	if o.tls != nil {
+		fd = tls.Server(fd, o.tls)
+	}
+	c := newConn(fd)
+	fd.SetReadDeadline(time.Now().Add(o.timeout))
+	p, err := c.Read()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	if p.Header().ID != pdu.OutbindID {
+		c.Close()
+		return nil, fmt.Errorf("unexpected PDU, want Outbind: %s",
+			p.Header().ID)
+	}
+	f := p.Fields()
+	id := f[pdufield.SystemID]
+	pw := f[pdufield.Password]
+	if id == nil || pw == nil {
+		c.Close()
+		return nil, errors.New("malformed Outbind, missing system_id/password")
+	}
+	if id.String() != o.user || pw.String() != o.passwd {
+		c.Close()
+		return nil, errors.New("invalid Outbind system_id/password")
+	}
+	fd.SetReadDeadline(time.Time{})
+	return c, nil
+}
+
+// Close closes the listener and the connection waiting for the
+// Outbind, if any.
+func (o *outbindListener) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	if o.fd != nil {
+		o.fd.Close()
+	}
+	return o.l.Close()
+}
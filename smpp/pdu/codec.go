@@ -152,7 +152,8 @@ func Decode(r io.Reader) (decoded Body, header *Header, raw []byte, err error) {
 	}
 	switch header.ID {
 	case AlertNotificationID:
-		// TODO(fiorix): Implement AlertNotification.
+		decoded, err = DecodeFields(newAlertNotification(header, raw), raw)
+		return
 	case BindReceiverID, BindTransceiverID, BindTransmitterID:
 		decoded, err = DecodeFields(newBind(header, raw), raw)
 		return
@@ -160,14 +161,17 @@ func Decode(r io.Reader) (decoded Body, header *Header, raw []byte, err error) {
 		decoded, err = DecodeFields(newBindResp(header, raw), raw)
 		return
 	case CancelSMID:
-		// TODO(fiorix): Implement CancelSM.
+		decoded, err = DecodeFields(newCancelSM(header, raw), raw)
+		return
 	case CancelSMRespID:
-		// TODO(fiorix): Implement CancelSMResp.
+		decoded, err = DecodeFields(newCancelSMResp(header, raw), raw)
+		return
 	case DataSMID:
 		decoded, err = DecodeFields(newDataSM(header, raw), raw)
 		return
 	case DataSMRespID:
-		// TODO(fiorix): Implement DataSMResp.
+		decoded, err = DecodeFields(newDataSMResp(header, raw), raw)
+		return
 	case DeliverSMID:
 		decoded, err = DecodeFields(newDeliverSM(header, raw), raw)
 		return
@@ -184,7 +188,8 @@ func Decode(r io.Reader) (decoded Body, header *Header, raw []byte, err error) {
 		decoded, err = DecodeFields(newGenericNACK(header), raw)
 		return
 	case OutbindID:
-		// TODO(fiorix): Implement Outbind.
+		decoded, err = DecodeFields(newOutbind(header, raw), raw)
+		return
 	case QuerySMID:
 		decoded, err = DecodeFields(newQuerySM(header, raw), raw)
 		return
@@ -192,9 +197,11 @@ func Decode(r io.Reader) (decoded Body, header *Header, raw []byte, err error) {
 		decoded, err = DecodeFields(newQuerySMResp(header, raw), raw)
 		return
 	case ReplaceSMID:
-		// TODO(fiorix): Implement ReplaceSM.
+		decoded, err = DecodeFields(newReplaceSM(header, raw), raw)
+		return
 	case ReplaceSMRespID:
-		// TODO(fiorix): Implement ReplaceSMResp.
+		decoded, err = DecodeFields(newReplaceSMResp(header, raw), raw)
+		return
 	case SubmitMultiID:
 		decoded, err = DecodeFields(newSubmitMulti(header, raw), raw)
 		return
@@ -215,8 +222,6 @@ func Decode(r io.Reader) (decoded Body, header *Header, raw []byte, err error) {
 		return
 	default:
 		err = fmt.Errorf("unknown PDU type: %#x", header.ID)
-		return
 	}
-	err = fmt.Errorf("PDU not implemented: %#x", header.ID)
 	return
 }
@@ -26,6 +26,8 @@ func New(n Name, data []byte) Body {
 		DestAddrNPI,
 		DestAddrTON,
 		ESMClass,
+		ESMEAddrNPI,
+		ESMEAddrTON,
 		ErrorCode,
 		InterfaceVersion,
 		MessageState,
@@ -48,6 +50,7 @@ func New(n Name, data []byte) Body {
 		AddressRange,
 		DestinationAddr,
 		DestinationList,
+		ESMEAddr,
 		FinalDate,
 		MessageID,
 		Password,
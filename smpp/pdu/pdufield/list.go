@@ -15,9 +15,9 @@ type List []Name
 
 // Decode decodes binary data in the given buffer to build a Map.
 //
-// If the ShortMessage field is present, and DataCoding as well,
-// we attempt to decode text automatically. See pdutext package
-// for more information.
+// The ShortMessage field is decoded as raw bytes. Use the Text method
+// of the PDU, or pdutext.DecodeSM with the DataCoding field, to decode
+// its text.
 func (l List) Decode(r *bytes.Buffer) (Map, error) {
 	var (
 		unsuccessCount, numDest, udhLength, smLength int
@@ -31,6 +31,7 @@ loop:
 		case
 			AddressRange,
 			DestinationAddr,
+			ESMEAddr,
 			ErrorCode,
 			FinalDate,
 			MessageID,
@@ -57,6 +58,8 @@ loop:
 			DestAddrNPI,
 			DestAddrTON,
 			ESMClass,
+			ESMEAddrNPI,
+			ESMEAddrTON,
 			InterfaceVersion,
 			NumberDests,
 			NoUnsuccess,
@@ -24,6 +24,9 @@ const (
 	DestinationAddr      Name = "destination_addr"
 	DestinationList      Name = "dest_addresses"
 	ESMClass             Name = "esm_class"
+	ESMEAddr             Name = "esme_addr"
+	ESMEAddrNPI          Name = "esme_addr_npi"
+	ESMEAddrTON          Name = "esme_addr_ton"
 	ErrorCode            Name = "error_code"
 	FinalDate            Name = "final_date"
 	InterfaceVersion     Name = "interface_version"
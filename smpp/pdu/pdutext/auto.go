@@ -0,0 +1,142 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+// Auto text codec, which selects the data coding of the text. The text
+// is encoded in GSM 7-bit (unpacked) if possible, or else in the first
+// of Latin1, ISO-8859-5, ISO-8859-8 or UCS2 that can represent it.
+type Auto []byte
+
+// Type implements the Codec interface.
+func (s Auto) Type() DataCoding {
+	return s.Codec().Type()
+}
+
+// Encode text with the selected codec.
+func (s Auto) Encode() []byte {
+	return s.Codec().Encode()
+}
+
+// Decode returns the text as is, because the data coding of encoded
+// text can't be inferred from it.
+func (s Auto) Decode() []byte {
+	return s
+}
+
+// Codec returns the codec selected for the text.
+func (s Auto) Codec() Codec {
+	text := string(s)
+	if len(encoding.ValidateGSM7String(text)) == 0 {
+		return GSM7(s)
+	}
+	if isLatin1(text) {
+		return Latin1(s)
+	}
+	if _, _, err := transform.String(charmap.ISO8859_5.NewEncoder(), text); err == nil {
+		return ISO88595(s)
+	}
+	if _, _, err := transform.String(charmap.ISO8859_8.NewEncoder(), text); err == nil {
+		return ISO88598(s)
+	}
+	return UCS2(s)
+}
+
+// AutoTransliterate is like Auto, but replaces characters that are not
+// in the GSM 7-bit alphabet with common equivalents, such as smart
+// quotes with ASCII quotes, when that keeps the text in GSM 7-bit.
+type AutoTransliterate []byte
+
+// Type implements the Codec interface.
+func (s AutoTransliterate) Type() DataCoding {
+	return s.Codec().Type()
+}
+
+// Encode text with the selected codec.
+func (s AutoTransliterate) Encode() []byte {
+	return s.Codec().Encode()
+}
+
+// Decode returns the text as is, because the data coding of encoded
+// text can't be inferred from it.
+func (s AutoTransliterate) Decode() []byte {
+	return s
+}
+
+// Codec returns the codec selected for the text, after transliteration.
+func (s AutoTransliterate) Codec() Codec {
+	text := Transliterate(string(s))
+	if len(encoding.ValidateGSM7String(text)) == 0 {
+		return GSM7(text)
+	}
+	return Auto(s).Codec()
+}
+
+// transliterations of characters not in the GSM 7-bit alphabet.
+var transliterations = strings.NewReplacer(
+	"\u00a0", " ", // no-break space
+	"\u2002", " ", // en space
+	"\u2003", " ", // em space
+	"\u2009", " ", // thin space
+	"\u200b", "", // zero width space
+	"‐", "-", // hyphen
+	"‑", "-", // non-breaking hyphen
+	"‒", "-", // figure dash
+	"–", "-", // en dash
+	"—", "-", // em dash
+	"―", "-", // horizontal bar
+	"−", "-", // minus sign
+	"‘", "'", // left single quotation mark
+	"’", "'", // right single quotation mark
+	"‚", "'", // single low-9 quotation mark
+	"‛", "'", // single high-reversed-9 quotation mark
+	"′", "'", // prime
+	"´", "'", // acute accent
+	"`", "'", // grave accent
+	"“", "\"", // left double quotation mark
+	"”", "\"", // right double quotation mark
+	"„", "\"", // double low-9 quotation mark
+	"‟", "\"", // double high-reversed-9 quotation mark
+	"″", "\"", // double prime
+	"«", "\"", // left-pointing double angle quotation mark
+	"»", "\"", // right-pointing double angle quotation mark
+	"‹", "'", // single left-pointing angle quotation mark
+	"›", "'", // single right-pointing angle quotation mark
+	"…", "...", // horizontal ellipsis
+	"•", "*", // bullet
+	"·", ".", // middle dot
+	"ˆ", "^", // modifier letter circumflex accent
+	"˜", "~", // small tilde
+	"×", "x", // multiplication sign
+	"÷", "/", // division sign
+	"™", "TM", // trade mark sign
+	"©", "(C)", // copyright sign
+	"®", "(R)", // registered sign
+)
+
+// Transliterate replaces characters that are not in the GSM 7-bit
+// alphabet with common equivalents that are, such as smart quotes and
+// dashes with their ASCII counterparts.
+func Transliterate(text string) string {
+	return transliterations.Replace(text)
+}
+
+// isLatin1 returns true if text can be encoded in ISO-8859-1.
+func isLatin1(text string) bool {
+	for _, r := range text {
+		if r >= 0x80 && r < 0xa0 || r > 0xff {
+			return false
+		}
+	}
+	return true
+}
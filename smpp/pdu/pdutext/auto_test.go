@@ -0,0 +1,47 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAuto(t *testing.T) {
+	test := []struct {
+		text     string
+		want     DataCoding
+		translit DataCoding
+	}{
+		{"Hello {world} €", DefaultType, DefaultType},
+		{"It’s “quoted” – ok…", UCS2Type, DefaultType},
+		{"Olá, você", Latin1Type, Latin1Type},
+		{"Привет", ISO88595Type, ISO88595Type},
+		{"שלום", ISO88598Type, ISO88598Type},
+		{"Привет “мир”", UCS2Type, UCS2Type},
+		{"你好", UCS2Type, UCS2Type},
+	}
+	for _, tc := range test {
+		if have := Auto(tc.text).Type(); have != tc.want {
+			t.Fatalf("unexpected data coding for %q: want %#x, have %#x",
+				tc.text, tc.want, have)
+		}
+		if have := AutoTransliterate(tc.text).Type(); have != tc.translit {
+			t.Fatalf("unexpected transliterated data coding for %q: want %#x, have %#x",
+				tc.text, tc.translit, have)
+		}
+	}
+}
+
+func TestAutoEncode(t *testing.T) {
+	text := "Olá, você"
+	if want, have := Latin1(text).Encode(), Auto(text).Encode(); !bytes.Equal(want, have) {
+		t.Fatalf("unexpected text: want %q, have %q", want, have)
+	}
+	want := GSM7("It's \"quoted\" - ok...").Encode()
+	if have := AutoTransliterate("It’s “quoted” – ok…").Encode(); !bytes.Equal(want, have) {
+		t.Fatalf("unexpected text: want %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+// Binary codec, for 8-bit data that is not text.
+type Binary []byte
+
+// Type implements the Codec interface.
+func (s Binary) Type() DataCoding {
+	return BinaryType
+}
+
+// Encode returns the data as is.
+func (s Binary) Encode() []byte {
+	return s
+}
+
+// Decode returns the data as is.
+func (s Binary) Decode() []byte {
+	return s
+}
+
+// Binary2 codec, like Binary with the alternative data coding 0x04.
+type Binary2 []byte
+
+// Type implements the Codec interface.
+func (s Binary2) Type() DataCoding {
+	return Binary2Type
+}
+
+// Encode returns the data as is.
+func (s Binary2) Encode() []byte {
+	return s
+}
+
+// Decode returns the data as is.
+func (s Binary2) Decode() []byte {
+	return s
+}
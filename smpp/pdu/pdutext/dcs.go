@@ -0,0 +1,225 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import "fmt"
+
+// CodingGroup is a coding group of the GSM 03.38 data coding scheme.
+type CodingGroup uint8
+
+// Coding groups.
+const (
+	GeneralGroup      CodingGroup = iota // 00xx, general data coding indication
+	AutoDeleteGroup                      // 01xx, message marked for automatic deletion
+	ReservedGroup                        // 1000 to 1011
+	DiscardGroup                         // 1100, message waiting indication, discard message
+	StoreGroup                           // 1101 and 1110, message waiting indication, store message
+	MessageClassGroup                    // 1111, data coding and message class
+)
+
+// String implements the fmt.Stringer interface.
+func (g CodingGroup) String() string {
+	switch g {
+	case GeneralGroup:
+		return "General"
+	case AutoDeleteGroup:
+		return "AutoDelete"
+	case ReservedGroup:
+		return "Reserved"
+	case DiscardGroup:
+		return "Discard"
+	case StoreGroup:
+		return "Store"
+	case MessageClassGroup:
+		return "MessageClass"
+	}
+	return fmt.Sprintf("CodingGroup(%d)", uint8(g))
+}
+
+// MessageClass is the class of a short message.
+type MessageClass uint8
+
+// Message classes.
+const (
+	NoClass MessageClass = iota
+	Class0               // Flash message, displayed immediately.
+	Class1               // ME specific.
+	Class2               // SIM specific.
+	Class3               // TE specific.
+)
+
+// String implements the fmt.Stringer interface.
+func (c MessageClass) String() string {
+	if c == NoClass {
+		return "NoClass"
+	}
+	if c <= Class3 {
+		return fmt.Sprintf("Class%d", uint8(c)-1)
+	}
+	return fmt.Sprintf("MessageClass(%d)", uint8(c))
+}
+
+// Indication is the type of a message waiting indication.
+type Indication uint8
+
+// Message waiting indication types.
+const (
+	VoicemailWaiting Indication = iota
+	FaxWaiting
+	EmailWaiting
+	OtherWaiting
+)
+
+// String implements the fmt.Stringer interface.
+func (i Indication) String() string {
+	switch i {
+	case VoicemailWaiting:
+		return "Voicemail"
+	case FaxWaiting:
+		return "Fax"
+	case EmailWaiting:
+		return "Email"
+	case OtherWaiting:
+		return "Other"
+	}
+	return fmt.Sprintf("Indication(%d)", uint8(i))
+}
+
+// DCS is a data coding scheme, as defined by GSM 03.38, that can be
+// sent as data_coding. In the general group, data codings 0x00 to
+// 0x0F are interpreted as defined by SMPP.
+//
+// The zero value is the SMSC default alphabet.
+type DCS struct {
+	Group      CodingGroup
+	Alphabet   DataCoding   // DefaultType, BinaryType or UCS2Type, or any SMPP data coding in the general group without class.
+	Class      MessageClass // General, automatic deletion and message class groups.
+	Compressed bool         // General and automatic deletion groups.
+	Indication Indication   // Message waiting indication groups.
+	Active     bool         // Message waiting indication groups: set or clear the indication.
+}
+
+// DCS returns the data coding scheme of c.
+func (c DataCoding) DCS() DCS {
+	switch {
+	case c < 0x10:
+		return DCS{Alphabet: c}
+	case c < 0x80:
+		d := DCS{
+			Alphabet:   alphabet(c),
+			Compressed: c&0x20 != 0,
+		}
+		if c&0x40 != 0 {
+			d.Group = AutoDeleteGroup
+		}
+		if c&0x10 != 0 {
+			d.Class = MessageClass(c&0x03) + Class0
+		}
+		return d
+	case c < 0xC0:
+		return DCS{Group: ReservedGroup}
+	case c < 0xF0:
+		d := DCS{
+			Group:      StoreGroup,
+			Indication: Indication(c & 0x03),
+			Active:     c&0x08 != 0,
+		}
+		if c < 0xD0 {
+			d.Group = DiscardGroup
+		}
+		if c >= 0xE0 {
+			d.Alphabet = UCS2Type
+		}
+		return d
+	}
+	d := DCS{
+		Group: MessageClassGroup,
+		Class: MessageClass(c&0x03) + Class0,
+	}
+	if c&0x04 != 0 {
+		d.Alphabet = BinaryType
+	}
+	return d
+}
+
+// alphabet returns the alphabet of the general data coding groups.
+// Reserved alphabets are assumed to be the GSM 7-bit default alphabet.
+func alphabet(c DataCoding) DataCoding {
+	switch c & 0x0C {
+	case 0x04:
+		return BinaryType
+	case 0x08:
+		return UCS2Type
+	}
+	return DefaultType
+}
+
+// DataCoding returns the data_coding value of d. It returns an error
+// if the fields of d can't be represented in its coding group, for
+// example a Latin1 flash message.
+func (d DCS) DataCoding() (DataCoding, error) {
+	var c DataCoding
+	switch d.Group {
+	case GeneralGroup, AutoDeleteGroup:
+		if d.Group == GeneralGroup && d.Class == NoClass && !d.Compressed && d.Alphabet < 0x10 {
+			return d.Alphabet, nil
+		}
+		switch d.Alphabet {
+		case DefaultType:
+		case BinaryType, Binary2Type:
+			c = 0x04
+		case UCS2Type:
+			c = 0x08
+		default:
+			return 0, fmt.Errorf("invalid alphabet for %s coding group: %#x", d.Group, d.Alphabet)
+		}
+		if d.Group == AutoDeleteGroup {
+			c |= 0x40
+		}
+		if d.Compressed {
+			c |= 0x20
+		}
+		switch {
+		case d.Class == NoClass:
+		case d.Class <= Class3:
+			c |= 0x10 | DataCoding(d.Class-Class0)
+		default:
+			return 0, fmt.Errorf("invalid message class: %s", d.Class)
+		}
+		return c, nil
+	case DiscardGroup, StoreGroup:
+		switch {
+		case d.Alphabet == DefaultType && d.Group == DiscardGroup:
+			c = 0xC0
+		case d.Alphabet == DefaultType:
+			c = 0xD0
+		case d.Alphabet == UCS2Type && d.Group == StoreGroup:
+			c = 0xE0
+		default:
+			return 0, fmt.Errorf("invalid alphabet for %s coding group: %#x", d.Group, d.Alphabet)
+		}
+		if d.Indication > OtherWaiting {
+			return 0, fmt.Errorf("invalid indication: %s", d.Indication)
+		}
+		if d.Active {
+			c |= 0x08
+		}
+		return c | DataCoding(d.Indication), nil
+	case MessageClassGroup:
+		c = 0xF0
+		switch d.Alphabet {
+		case DefaultType:
+		case BinaryType, Binary2Type:
+			c |= 0x04
+		default:
+			return 0, fmt.Errorf("invalid alphabet for %s coding group: %#x", d.Group, d.Alphabet)
+		}
+		if d.Class == NoClass || d.Class > Class3 {
+			return 0, fmt.Errorf("invalid message class: %s", d.Class)
+		}
+		return c | DataCoding(d.Class-Class0), nil
+	}
+	return 0, fmt.Errorf("invalid coding group: %s", d.Group)
+}
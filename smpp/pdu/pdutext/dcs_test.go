@@ -0,0 +1,96 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import "testing"
+
+func TestDCS(t *testing.T) {
+	test := []struct {
+		coding DataCoding
+		dcs    DCS
+	}{
+		{0x00, DCS{}},
+		{0x03, DCS{Alphabet: Latin1Type}},
+		{0x08, DCS{Alphabet: UCS2Type}},
+		{0x0E, DCS{Alphabet: KSC5601Type}},
+		{0x10, DCS{Class: Class0}},
+		{0x15, DCS{Alphabet: BinaryType, Class: Class1}},
+		{0x18, DCS{Alphabet: UCS2Type, Class: Class0}},
+		{0x20, DCS{Compressed: true}},
+		{0x3B, DCS{Alphabet: UCS2Type, Class: Class3, Compressed: true}},
+		{0x40, DCS{Group: AutoDeleteGroup}},
+		{0x5A, DCS{Group: AutoDeleteGroup, Alphabet: UCS2Type, Class: Class2}},
+		{0xC0, DCS{Group: DiscardGroup}},
+		{0xC8, DCS{Group: DiscardGroup, Active: true}},
+		{0xD9, DCS{Group: StoreGroup, Indication: FaxWaiting, Active: true}},
+		{0xEA, DCS{Group: StoreGroup, Alphabet: UCS2Type, Indication: EmailWaiting, Active: true}},
+		{0xF0, DCS{Group: MessageClassGroup, Class: Class0}},
+		{0xF7, DCS{Group: MessageClassGroup, Alphabet: BinaryType, Class: Class3}},
+	}
+	for _, tc := range test {
+		if d := tc.coding.DCS(); d != tc.dcs {
+			t.Fatalf("unexpected DCS for %#x:\nwant: %+v\nhave: %+v", tc.coding, tc.dcs, d)
+		}
+		c, err := tc.dcs.DataCoding()
+		if err != nil {
+			t.Fatalf("%+v: %v", tc.dcs, err)
+		}
+		if c != tc.coding {
+			t.Fatalf("unexpected data coding for %+v: want %#x, have %#x", tc.dcs, tc.coding, c)
+		}
+	}
+}
+
+func TestDCSReserved(t *testing.T) {
+	// Reserved alphabet, assumed to be GSM 7-bit.
+	if d := DataCoding(0x1C).DCS(); d != (DCS{Class: Class0}) {
+		t.Fatalf("unexpected DCS for 0x1c: %+v", d)
+	}
+	if d := DataCoding(0x9F).DCS(); d != (DCS{Group: ReservedGroup}) {
+		t.Fatalf("unexpected DCS for 0x9f: %+v", d)
+	}
+	if _, err := (DCS{Group: ReservedGroup}).DataCoding(); err == nil {
+		t.Fatal("want error for reserved coding group, have nil")
+	}
+}
+
+func TestDCSErrors(t *testing.T) {
+	test := []DCS{
+		{Alphabet: Latin1Type, Class: Class0},
+		{Alphabet: 0x10},
+		{Class: Class3 + 1},
+		{Group: AutoDeleteGroup, Alphabet: ISO88595Type},
+		{Group: DiscardGroup, Alphabet: UCS2Type},
+		{Group: StoreGroup, Alphabet: BinaryType},
+		{Group: StoreGroup, Indication: OtherWaiting + 1},
+		{Group: MessageClassGroup, Alphabet: UCS2Type, Class: Class0},
+		{Group: MessageClassGroup},
+		{Group: MessageClassGroup + 1},
+	}
+	for _, d := range test {
+		if c, err := d.DataCoding(); err == nil {
+			t.Fatalf("want error for %+v, have %#x", d, c)
+		}
+	}
+}
+
+func TestDCSString(t *testing.T) {
+	test := []struct {
+		v    interface{ String() string }
+		want string
+	}{
+		{NoClass, "NoClass"},
+		{Class0, "Class0"},
+		{Class3, "Class3"},
+		{MessageClass(9), "MessageClass(9)"},
+		{StoreGroup, "Store"},
+		{VoicemailWaiting, "Voicemail"},
+	}
+	for _, tc := range test {
+		if s := tc.v.String(); s != tc.want {
+			t.Fatalf("unexpected string: want %q, have %q", tc.want, s)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"errors"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+// ErrCompressed is returned by DecodeSM for short messages compressed
+// as indicated by the GSM 03.38 data coding scheme, which can't be
+// decoded.
+var ErrCompressed = errors.New("compressed short message")
+
+// DecodeSM decodes the short message sm, without user data header,
+// according to the data coding c, and returns its text.
+//
+// The alphabet of the text is that of the data coding scheme returned
+// by c.DCS: values 0x00 to 0x0F of c are interpreted as defined by
+// SMPP, and other values as defined by GSM 03.38, with reserved coding
+// groups assumed to be GSM 7-bit. Text in GSM
+// 7-bit is expected to be unpacked, as in the GSM7 codec. Binary data
+// and text in unsupported codings, such as pictograms, are returned as
+// is. It returns ErrCompressed if the text is compressed.
+func DecodeSM(c DataCoding, sm []byte) (string, error) {
+	return DecodeSMLanguage(c, sm, encoding.DefaultLanguage, encoding.DefaultLanguage)
+}
+
+// DecodeSMLanguage is like DecodeSM, but decodes GSM 7-bit text with
+// the given national language locking shift and single shift tables,
+// as sent in the user data header IEIs 0x25 and 0x24.
+func DecodeSMLanguage(c DataCoding, sm []byte, locking, single encoding.Language) (string, error) {
+	d := c.DCS()
+	if d.Compressed {
+		return "", ErrCompressed
+	}
+	var s Codec
+	switch d.Alphabet {
+	case DefaultType:
+		s = GSM7National{Text: sm, Locking: locking, Single: single}
+	case IA5Type:
+		s = IA5(sm)
+	case Latin1Type:
+		s = Latin1(sm)
+	case JISType:
+		s = JIS(sm)
+	case ISO88595Type:
+		s = ISO88595(sm)
+	case ISO88598Type:
+		s = ISO88598(sm)
+	case UCS2Type:
+		s = UCS2(sm)
+	case ISO2022JPType:
+		s = ISO2022JP(sm)
+	case EXTJISType:
+		s = EXTJIS(sm)
+	case KSC5601Type:
+		s = KSC5601(sm)
+	default:
+		s = Raw(sm)
+	}
+	return string(s.Decode()), nil
+}
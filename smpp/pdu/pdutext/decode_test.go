@@ -0,0 +1,69 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"testing"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+func TestDecodeSM(t *testing.T) {
+	test := []struct {
+		coding DataCoding
+		sm     string
+		want   string
+	}{
+		{0x00, "Hello \x1b\x65", "Hello €"},
+		{0x01, "abc", "abc"},
+		{0x02, "\x00\xff", "\x00\xff"},
+		{0x03, "mo\xe7o", "moço"},
+		{0x04, "\x00\xff", "\x00\xff"},
+		{0x05, "\x93\xfa\x96\x7b", "日本"},
+		{0x06, string(iso88595Bytes), string(iso88595UTF8Bytes)},
+		{0x07, "\xf9\xec\xe5\xed", "שלום"},
+		{0x08, "\x00m\x00o\x00\xe7\x00o", "moço"},
+		{0x09, "\x01\x02", "\x01\x02"},
+		{0x0A, "\x1b$BF|K\\\x1b(B", "日本"},
+		{0x0D, "\xc6\xfc\xcb\xdc", "日本"},
+		{0x0E, "\xc7\xd1\xb1\xb9", "한국"},
+		{0x10, "Flash", "Flash"},                  // class 0
+		{0x15, "\x00\xff", "\x00\xff"},            // 8-bit, class 1
+		{0x18, "\x00m\x00o\x00\xe7\x00o", "moço"}, // UCS2, class 0
+		{0x1C, "Reserved", "Reserved"},            // reserved alphabet
+		{0x48, "\x00m\x00o\x00\xe7\x00o", "moço"}, // automatic deletion
+		{0x80, "Reserved", "Reserved"},            // reserved group
+		{0xC8, "Voicemail", "Voicemail"},          // discard message
+		{0xD0, "Voicemail", "Voicemail"},          // store message
+		{0xE0, "\x00m\x00o\x00\xe7\x00o", "moço"}, // store message, UCS2
+		{0xF0, "Flash", "Flash"},                  // class 0
+		{0xF6, "\x00\xff", "\x00\xff"},            // 8-bit, class 2
+	}
+	for _, tc := range test {
+		have, err := DecodeSM(tc.coding, []byte(tc.sm))
+		if err != nil {
+			t.Fatalf("data coding %#x: %v", tc.coding, err)
+		}
+		if have != tc.want {
+			t.Fatalf("unexpected text for data coding %#x:\nwant: %q\nhave: %q",
+				tc.coding, tc.want, have)
+		}
+	}
+	for _, c := range []DataCoding{0x20, 0x39, 0x60} {
+		if _, err := DecodeSM(c, []byte("Hello")); err != ErrCompressed {
+			t.Fatalf("data coding %#x: want ErrCompressed, have %v", c, err)
+		}
+	}
+}
+
+func TestDecodeSMLanguage(t *testing.T) {
+	text, err := DecodeSMLanguage(0x00, []byte("\x1c\x1b\x53"), encoding.Turkish, encoding.Turkish)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ŞŞ"; text != want {
+		t.Fatalf("unexpected text: want %q, have %q", want, text)
+	}
+}
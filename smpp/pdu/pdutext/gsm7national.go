@@ -0,0 +1,68 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/transform"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+// UDHCodec is a Codec whose encoded text must be sent along with user
+// data header elements, such as the national language shift tables of
+// GSM7National.
+type UDHCodec interface {
+	Codec
+
+	// UDH returns the information elements of the user data header,
+	// without the header length.
+	UDH() []byte
+}
+
+// GSM7National text codec, GSM 7-bit (unpacked) with the national
+// language locking shift and single shift tables.
+type GSM7National struct {
+	Text    []byte
+	Locking encoding.Language // Locking shift table, UDH IEI 0x25.
+	Single  encoding.Language // Single shift table, UDH IEI 0x24.
+}
+
+// Type implements the Codec interface.
+func (s GSM7National) Type() DataCoding {
+	return DefaultType
+}
+
+// Encode to GSM 7-bit (unpacked) with the national language tables.
+func (s GSM7National) Encode() []byte {
+	e := encoding.GSM7Language(false, s.Locking, s.Single).NewEncoder()
+	es, _, err := transform.Bytes(e, s.Text)
+	if err != nil {
+		return s.Text
+	}
+	return es
+}
+
+// Decode from GSM 7-bit (unpacked) with the national language tables.
+func (s GSM7National) Decode() []byte {
+	e := encoding.GSM7Language(false, s.Locking, s.Single).NewDecoder()
+	es, _, err := transform.Bytes(e, s.Text)
+	if err != nil {
+		return s.Text
+	}
+	return es
+}
+
+// UDH implements the UDHCodec interface. It returns the information
+// elements of the tables other than the default ones.
+func (s GSM7National) UDH() []byte {
+	var ies []byte
+	if s.Single != encoding.DefaultLanguage {
+		ies = append(ies, 0x24, 1, byte(s.Single))
+	}
+	if s.Locking != encoding.DefaultLanguage {
+		ies = append(ies, 0x25, 1, byte(s.Locking))
+	}
+	return ies
+}
@@ -0,0 +1,31 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+// IA5 text codec, for IA5 (CCITT T.50) / ASCII text. Characters
+// outside of ASCII are encoded as '?'.
+type IA5 []byte
+
+// Type implements the Codec interface.
+func (s IA5) Type() DataCoding {
+	return IA5Type
+}
+
+// Encode to IA5.
+func (s IA5) Encode() []byte {
+	es := make([]byte, 0, len(s))
+	for _, r := range string(s) {
+		if r >= 0x80 {
+			r = '?'
+		}
+		es = append(es, byte(r))
+	}
+	return es
+}
+
+// Decode from IA5.
+func (s IA5) Decode() []byte {
+	return IA5(s).Encode()
+}
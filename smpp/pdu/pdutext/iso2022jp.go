@@ -0,0 +1,38 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// ISO2022JP text codec.
+type ISO2022JP []byte
+
+// Type implements the Codec interface.
+func (s ISO2022JP) Type() DataCoding {
+	return ISO2022JPType
+}
+
+// Encode to ISO2022JP.
+func (s ISO2022JP) Encode() []byte {
+	e := japanese.ISO2022JP.NewEncoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
+
+// Decode from ISO2022JP.
+func (s ISO2022JP) Decode() []byte {
+	e := japanese.ISO2022JP.NewDecoder()
+	es, _, err := transform.Bytes(e, s)
+	if err != nil {
+		return s
+	}
+	return es
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import "unicode/utf8"
+
+// MaxPayloadLen is the maximum length in octets of the message_payload
+// TLV.
+const MaxPayloadLen = 65535
+
+// Segments describes how a text is split into short messages by each
+// of the strategies supported by Transmitter.SubmitLongMsg.
+type Segments struct {
+	Coding  DataCoding   // Data coding of the encoded text.
+	Chars   int          // Number of characters of the text.
+	Units   int          // Length of the encoded text, in septets for GSM 7-bit or octets otherwise.
+	UDH16   SegmentCount // Segments with a UDH with 16-bit reference number, the default.
+	UDH8    SegmentCount // Segments with a UDH with 8-bit reference number.
+	SAR     SegmentCount // Segments without UDH, sent with the sar_* TLVs.
+	Payload SegmentCount // Single message with the message_payload TLV.
+}
+
+// SegmentCount is the number of segments of a text, and the room left
+// in the last one.
+type SegmentCount struct {
+	Segments  int // Number of segments.
+	Remaining int // Characters that still fit in the last segment.
+}
+
+// CountSegments returns how the text of c is split into short messages,
+// consistently with Split. Remaining characters are counted as
+// characters of the basic alphabet, which take one septet in GSM 7-bit
+// and one code unit in UCS2. For the Auto codecs, Coding is the data
+// coding they select.
+func CountSegments(c Codec) Segments {
+	switch t := c.(type) {
+	case Auto:
+		c = t.Codec()
+	case AutoTransliterate:
+		c = t.Codec()
+	}
+	s := Segments{Coding: c.Type()}
+	unit := 1 // octets per unit
+	payloadMax := MaxPayloadLen
+	switch t := c.(type) {
+	case GSM7:
+		s.Chars = utf8.RuneCount(t)
+	case GSM7Packed:
+		s.Chars = utf8.RuneCount(t)
+		c = GSM7(t) // count septets
+		payloadMax = MaxPayloadLen * 8 / 7
+	case GSM7National:
+		s.Chars = utf8.RuneCount(t.Text)
+	case UCS2:
+		s.Chars = utf8.RuneCount(t)
+		unit = 2
+	case Latin1:
+		s.Chars = utf8.RuneCount(t)
+	case ISO88595:
+		s.Chars = utf8.RuneCount(t)
+	case ISO88598:
+		s.Chars = utf8.RuneCount(t)
+	case IA5:
+		s.Chars = utf8.RuneCount(t)
+	case JIS:
+		s.Chars = utf8.RuneCount(t)
+	case EXTJIS:
+		s.Chars = utf8.RuneCount(t)
+	case ISO2022JP:
+		s.Chars = utf8.RuneCount(t)
+	case KSC5601:
+		s.Chars = utf8.RuneCount(t)
+	}
+	var ies int // length of the information elements required by c
+	if u, ok := c.(UDHCodec); ok {
+		ies = len(u.UDH())
+	}
+	count := func(udhLen int) SegmentCount {
+		if ies > 0 {
+			if udhLen == 0 {
+				udhLen = 1 // header length
+			}
+			udhLen += ies
+		}
+		segs := Split(c, udhLen)
+		max := (MaxLen - udhLen) / unit
+		switch c.(type) {
+		case GSM7, GSM7National:
+			max = (MaxLen - udhLen) * 8 / 7
+		}
+		return SegmentCount{
+			Segments:  len(segs),
+			Remaining: max - len(segs[len(segs)-1])/unit,
+		}
+	}
+	for _, seg := range Split(c, 0) {
+		s.Units += len(seg)
+	}
+	if s.Chars == 0 {
+		s.Chars = s.Units
+	}
+	s.UDH16 = count(7)
+	s.UDH8 = count(6)
+	s.SAR = count(0)
+	s.Payload = SegmentCount{
+		Segments:  1,
+		Remaining: payloadMax/unit - s.Units/unit,
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountSegments(t *testing.T) {
+	test := []struct {
+		codec Codec
+		want  Segments
+	}{
+		{GSM7("Hello world"), Segments{
+			Coding:  DefaultType,
+			Chars:   11,
+			Units:   11,
+			UDH16:   SegmentCount{1, 141},
+			UDH8:    SegmentCount{1, 142},
+			SAR:     SegmentCount{1, 149},
+			Payload: SegmentCount{1, 65524},
+		}},
+		{GSM7Packed(strings.Repeat("€", 100)), Segments{
+			Coding:  DefaultType,
+			Chars:   100,
+			Units:   200,
+			UDH16:   SegmentCount{2, 104},
+			UDH8:    SegmentCount{2, 105},
+			SAR:     SegmentCount{2, 120},
+			Payload: SegmentCount{1, 74697},
+		}},
+		{UCS2(strings.Repeat("á", 70) + "😀"), Segments{
+			Coding:  UCS2Type,
+			Chars:   71,
+			Units:   144,
+			UDH16:   SegmentCount{2, 60},
+			UDH8:    SegmentCount{2, 62},
+			SAR:     SegmentCount{2, 68},
+			Payload: SegmentCount{1, 32695},
+		}},
+		{Latin1(strings.Repeat("á", 140)), Segments{
+			Coding:  Latin1Type,
+			Chars:   140,
+			Units:   140,
+			UDH16:   SegmentCount{2, 126},
+			UDH8:    SegmentCount{2, 128},
+			SAR:     SegmentCount{1, 0},
+			Payload: SegmentCount{1, 65395},
+		}},
+	}
+	for _, tc := range test {
+		have := CountSegments(tc.codec)
+		if have != tc.want {
+			t.Fatalf("unexpected segments for %q:\nwant: %+v\nhave: %+v",
+				tc.codec, tc.want, have)
+		}
+	}
+}
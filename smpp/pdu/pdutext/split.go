@@ -0,0 +1,146 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	xencoding "golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+// MaxLen is the maximum length in octets of the user data of a short
+// message, including the user data header.
+const MaxLen = 140
+
+// Split encodes the text of c and splits it into segments that fit in
+// a short message along with a user data header of udhLen octets. It
+// returns the encoded segments, in order.
+//
+// Text is split between characters: GSM 7-bit escape sequences and
+// UCS2 surrogate pairs are never split across segments. GSM7 segments
+// hold up to 153 septets with a 6 octet UDH, and UCS2 segments up to
+// 67 code units. GSM7Packed segments are packed with the fill bits that
+// align them to a septet boundary after the UDH. Characters that are
+// not in the GSM 7-bit alphabet are replaced by '?'.
+//
+// Text in multibyte encodings, such as JIS or KSC5601, is split between
+// characters too. For a UDHCodec, udhLen must include the length of its
+// information elements. Codecs of unknown character width are split
+// between octets.
+func Split(c Codec, udhLen int) [][]byte {
+	switch s := c.(type) {
+	case Auto:
+		return Split(s.Codec(), udhLen)
+	case AutoTransliterate:
+		return Split(s.Codec(), udhLen)
+	case GSM7:
+		return splitGSM7(string(s), udhLen, false, encoding.GSM7(false))
+	case GSM7Packed:
+		return splitGSM7(string(s), udhLen, true, encoding.GSM7(false))
+	case GSM7National:
+		return splitGSM7(string(s.Text), udhLen, false,
+			encoding.GSM7Language(false, s.Locking, s.Single))
+	case UCS2:
+		return splitUCS2(string(s), udhLen)
+	case JIS:
+		return splitEncoded(string(s), MaxLen-udhLen, func(b []byte) []byte { return JIS(b).Encode() })
+	case EXTJIS:
+		return splitEncoded(string(s), MaxLen-udhLen, func(b []byte) []byte { return EXTJIS(b).Encode() })
+	case ISO2022JP:
+		return splitEncoded(string(s), MaxLen-udhLen, func(b []byte) []byte { return ISO2022JP(b).Encode() })
+	case KSC5601:
+		return splitEncoded(string(s), MaxLen-udhLen, func(b []byte) []byte { return KSC5601(b).Encode() })
+	}
+	return splitOctets(c.Encode(), MaxLen-udhLen)
+}
+
+// splitOctets splits b into segments of up to n octets.
+func splitOctets(b []byte, n int) [][]byte {
+	var segs [][]byte
+	for len(b) > n {
+		segs = append(segs, b[:n])
+		b = b[n:]
+	}
+	return append(segs, b)
+}
+
+// splitEncoded splits text into segments of up to n octets encoded with
+// a multibyte encoding. Each segment is encoded on its own, so that
+// stateful encodings such as ISO-2022-JP can be decoded per segment.
+func splitEncoded(text string, n int, encode func([]byte) []byte) [][]byte {
+	var segs [][]byte
+	var seg, last []byte // text and encoded text of the current segment
+	for _, r := range text {
+		next := append(seg[:len(seg):len(seg)], string(r)...)
+		enc := encode(next)
+		if len(enc) > n && len(seg) > 0 {
+			segs = append(segs, last)
+			next = []byte(string(r))
+			enc = encode(next)
+		}
+		seg, last = next, enc
+	}
+	return append(segs, last)
+}
+
+// splitGSM7 splits text into segments of GSM 7-bit septets encoded
+// with enc, optionally packed after udhLen octets of user data header.
+func splitGSM7(text string, udhLen int, packed bool, enc xencoding.Encoding) [][]byte {
+	max := (MaxLen - udhLen) * 8 / 7
+	fill := (7 - udhLen*8%7) % 7
+	e := enc.NewEncoder()
+	var segs [][]byte
+	var seg []byte
+	for _, r := range text {
+		septets, _, err := transform.Bytes(e, []byte(string(r)))
+		if err != nil {
+			septets = []byte{'?'}
+		}
+		if len(seg)+len(septets) > max {
+			segs = append(segs, seg)
+			seg = nil
+		}
+		seg = append(seg, septets...)
+	}
+	segs = append(segs, seg)
+	if packed {
+		for i, seg := range segs {
+			segs[i] = packSeptets(seg, fill)
+		}
+	}
+	return segs
+}
+
+// packSeptets packs septets into octets, after fill zero bits.
+func packSeptets(septets []byte, fill int) []byte {
+	b := make([]byte, (fill+len(septets)*7+7)/8)
+	bit := fill
+	for _, s := range septets {
+		s &= 0x7F
+		b[bit/8] |= s << uint(bit%8)
+		if bit%8 > 1 {
+			b[bit/8+1] |= s >> uint(8-bit%8)
+		}
+		bit += 7
+	}
+	return b
+}
+
+// splitUCS2 splits text into segments of UCS2 code units.
+func splitUCS2(text string, udhLen int) [][]byte {
+	max := (MaxLen - udhLen) / 2 * 2
+	var segs [][]byte
+	var seg []byte
+	for _, r := range text {
+		units := UCS2(string(r)).Encode() // 2 octets, or 4 for surrogate pairs
+		if len(seg)+len(units) > max {
+			segs = append(segs, seg)
+			seg = nil
+		}
+		seg = append(seg, units...)
+	}
+	return append(segs, seg)
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutext
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+)
+
+func TestSplit(t *testing.T) {
+	test := []struct {
+		name   string
+		codec  Codec
+		udhLen int
+		want   []int // length of each segment
+	}{
+		{"gsm7 single", GSM7("Hello world"), 0, []int{11}},
+		{"gsm7 full", GSM7(strings.Repeat("a", 160)), 0, []int{160}},
+		{"gsm7 udh", GSM7(strings.Repeat("a", 306)), 6, []int{153, 153}},
+		{"gsm7 udh16", GSM7(strings.Repeat("a", 306)), 7, []int{152, 152, 2}},
+		{"gsm7 escape", GSM7(strings.Repeat("a", 152) + "€"), 6, []int{152, 2}},
+		{"gsm7 packed", GSM7Packed(strings.Repeat("a", 306)), 6, []int{134, 134}},
+		{"ucs2", UCS2(strings.Repeat("á", 134)), 6, []int{134, 134}},
+		{"ucs2 surrogate", UCS2(strings.Repeat("á", 66) + "😀"), 6, []int{132, 4}},
+		{"latin1", Latin1(strings.Repeat("á", 200)), 6, []int{134, 66}},
+		{"raw", Raw(strings.Repeat("a", 140)), 0, []int{140}},
+		{"ksc5601", KSC5601("a" + strings.Repeat("한", 70)), 0, []int{139, 2}},
+		{"iso2022jp", ISO2022JP(strings.Repeat("日", 70)), 0, []int{140, 12}},
+	}
+	for _, tc := range test {
+		segs := Split(tc.codec, tc.udhLen)
+		if len(segs) != len(tc.want) {
+			t.Fatalf("%s: unexpected number of segments: want %d, have %d",
+				tc.name, len(tc.want), len(segs))
+		}
+		for i, seg := range segs {
+			if len(seg) != tc.want[i] {
+				t.Fatalf("%s: unexpected length of segment %d: want %d, have %d",
+					tc.name, i, tc.want[i], len(seg))
+			}
+		}
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	text := strings.Repeat("Olá {mundo} 😀 ", 30)
+	var b bytes.Buffer
+	for _, seg := range Split(UCS2(text), 6) {
+		b.Write(UCS2(seg).Decode())
+	}
+	if b.String() != text {
+		t.Fatalf("unexpected text:\nwant: %q\nhave: %q", text, b.String())
+	}
+	text = strings.Repeat("こんにちは世界 ", 30)
+	b.Reset()
+	for _, seg := range Split(ISO2022JP(text), 6) {
+		b.Write(ISO2022JP(seg).Decode())
+	}
+	if b.String() != text {
+		t.Fatalf("unexpected text:\nwant: %q\nhave: %q", text, b.String())
+	}
+	text = strings.Repeat("Hello {world} ", 30)
+	b.Reset()
+	for _, seg := range Split(GSM7(text), 6) {
+		b.Write(GSM7(seg).Decode())
+	}
+	if b.String() != text {
+		t.Fatalf("unexpected text:\nwant: %q\nhave: %q", text, b.String())
+	}
+}
+
+func TestSplitPacked(t *testing.T) {
+	text := "Hello world"
+	// Without UDH, segments are packed as by the GSM7Packed codec.
+	segs := Split(GSM7Packed(text), 0)
+	if want := GSM7Packed(text).Encode(); !bytes.Equal(segs[0], want) {
+		t.Fatalf("unexpected packing: want %x, have %x", want, segs[0])
+	}
+	// With a 6 octet UDH, one fill bit aligns the text to a septet.
+	segs = Split(GSM7Packed("@A"), 6)
+	if want := []byte{0x00, 0x41}; !bytes.Equal(segs[0], want) {
+		t.Fatalf("unexpected packing: want %x, have %x", want, segs[0])
+	}
+}
+
+func TestSplitNational(t *testing.T) {
+	c := GSM7National{
+		Text:    []byte(strings.Repeat("ş", 150)),
+		Locking: encoding.Turkish,
+	}
+	if udh := c.UDH(); !bytes.Equal(udh, []byte{0x25, 1, 1}) {
+		t.Fatalf("unexpected udh: %x", udh)
+	}
+	// 7 octet concatenation UDH plus the locking shift element.
+	segs := Split(c, 10)
+	if len(segs) != 2 || len(segs[0]) != 148 {
+		t.Fatalf("unexpected segments: %d, %d octets", len(segs), len(segs[0]))
+	}
+	var b bytes.Buffer
+	for _, seg := range segs {
+		b.Write(GSM7National{Text: seg, Locking: encoding.Turkish}.Decode())
+	}
+	if b.String() != string(c.Text) {
+		t.Fatalf("unexpected text: %q", b.String())
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutlv
+
+import (
+	"fmt"
+)
+
+// Type is the type of the value of a TLV field.
+type Type uint8
+
+// TLV value types.
+const (
+	TypeOctets  Type = iota // Octet string.
+	TypeCString             // Null-terminated text string.
+	TypeUint8               // 1 octet integer.
+	TypeUint16              // 2 octet integer, big endian.
+	TypeUint32              // 4 octet integer, big endian.
+)
+
+// Def is the definition of the value of the TLV fields of a tag, as
+// in section 5.3.2 of the SMPP 3.4 specification.
+type Def struct {
+	Name   string // Name of the parameter.
+	Type   Type   // Type of the value.
+	MinLen int    // Minimum length of the value, in octets.
+	MaxLen int    // Maximum length of the value, in octets.
+
+	enum func(v uint32) fmt.Stringer // Enum type of integer values, if any.
+}
+
+// integer returns a Def of an integer TLV.
+func integer(name string, t Type, enum func(v uint32) fmt.Stringer) Def {
+	n := map[Type]int{TypeUint8: 1, TypeUint16: 2, TypeUint32: 4}[t]
+	return Def{Name: name, Type: t, MinLen: n, MaxLen: n, enum: enum}
+}
+
+var defs = map[Tag]Def{
+	TagDestAddrSubunit:          integer("dest_addr_subunit", TypeUint8, func(v uint32) fmt.Stringer { return AddrSubunit(v) }),
+	TagDestNetworkType:          integer("dest_network_type", TypeUint8, func(v uint32) fmt.Stringer { return NetworkType(v) }),
+	TagDestBearerType:           integer("dest_bearer_type", TypeUint8, func(v uint32) fmt.Stringer { return BearerType(v) }),
+	TagDestTelematicsID:         integer("dest_telematics_id", TypeUint16, nil),
+	TagSourceAddrSubunit:        integer("source_addr_subunit", TypeUint8, func(v uint32) fmt.Stringer { return AddrSubunit(v) }),
+	TagSourceNetworkType:        integer("source_network_type", TypeUint8, func(v uint32) fmt.Stringer { return NetworkType(v) }),
+	TagSourceBearerType:         integer("source_bearer_type", TypeUint8, func(v uint32) fmt.Stringer { return BearerType(v) }),
+	TagSourceTelematicsID:       integer("source_telematics_id", TypeUint8, nil),
+	TagQosTimeToLive:            integer("qos_time_to_live", TypeUint32, nil),
+	TagPayloadType:              integer("payload_type", TypeUint8, func(v uint32) fmt.Stringer { return PayloadType(v) }),
+	TagAdditionalStatusInfoText: {Name: "additional_status_info_text", Type: TypeCString, MinLen: 1, MaxLen: 256},
+	TagReceiptedMessageID:       {Name: "receipted_message_id", Type: TypeCString, MinLen: 1, MaxLen: 65},
+	TagMsMsgWaitFacilities:      integer("ms_msg_wait_facilities", TypeUint8, nil),
+	TagPrivacyIndicator:         integer("privacy_indicator", TypeUint8, func(v uint32) fmt.Stringer { return PrivacyIndicator(v) }),
+	TagSourceSubaddress:         {Name: "source_subaddress", Type: TypeOctets, MinLen: 2, MaxLen: 23},
+	TagDestSubaddress:           {Name: "dest_subaddress", Type: TypeOctets, MinLen: 2, MaxLen: 23},
+	TagUserMessageReference:     integer("user_message_reference", TypeUint16, nil),
+	TagUserResponseCode:         integer("user_response_code", TypeUint8, nil),
+	TagSourcePort:               integer("source_port", TypeUint16, nil),
+	TagDestinationPort:          integer("destination_port", TypeUint16, nil),
+	TagSarMsgRefNum:             integer("sar_msg_ref_num", TypeUint16, nil),
+	TagLanguageIndicator:        integer("language_indicator", TypeUint8, func(v uint32) fmt.Stringer { return LanguageIndicator(v) }),
+	TagSarTotalSegments:         integer("sar_total_segments", TypeUint8, nil),
+	TagSarSegmentSeqnum:         integer("sar_segment_seqnum", TypeUint8, nil),
+	TagScInterfaceVersion:       integer("SC_interface_version", TypeUint8, nil),
+	TagCallbackNumPresInd:       integer("callback_num_pres_ind", TypeUint8, nil),
+	TagCallbackNumAtag:          {Name: "callback_num_atag", Type: TypeOctets, MinLen: 0, MaxLen: 65},
+	TagNumberOfMessages:         integer("number_of_messages", TypeUint8, nil),
+	TagCallbackNum:              {Name: "callback_num", Type: TypeOctets, MinLen: 4, MaxLen: 19},
+	TagDpfResult:                integer("dpf_result", TypeUint8, nil),
+	TagSetDpf:                   integer("set_dpf", TypeUint8, nil),
+	TagMsAvailabilityStatus:     integer("ms_availability_status", TypeUint8, func(v uint32) fmt.Stringer { return MsAvailabilityStatus(v) }),
+	TagNetworkErrorCode:         {Name: "network_error_code", Type: TypeOctets, MinLen: 3, MaxLen: 3},
+	TagMessagePayload:           {Name: "message_payload", Type: TypeOctets, MinLen: 0, MaxLen: 0xFFFF},
+	TagDeliveryFailureReason:    integer("delivery_failure_reason", TypeUint8, func(v uint32) fmt.Stringer { return DeliveryFailureReason(v) }),
+	TagMoreMessagesToSend:       integer("more_messages_to_send", TypeUint8, nil),
+	TagMessageStateOption:       integer("message_state", TypeUint8, func(v uint32) fmt.Stringer { return MessageState(v) }),
+	TagUssdServiceOp:            integer("ussd_service_op", TypeUint8, func(v uint32) fmt.Stringer { return UssdServiceOp(v) }),
+	TagDisplayTime:              integer("display_time", TypeUint8, func(v uint32) fmt.Stringer { return DisplayTime(v) }),
+	TagSmsSignal:                integer("sms_signal", TypeUint16, nil),
+	TagMsValidity:               integer("ms_validity", TypeUint8, func(v uint32) fmt.Stringer { return MsValidity(v) }),
+	TagAlertOnMessageDelivery:   {Name: "alert_on_message_delivery", Type: TypeOctets, MinLen: 0, MaxLen: 1},
+	TagItsReplyType:             integer("its_reply_type", TypeUint8, func(v uint32) fmt.Stringer { return ItsReplyType(v) }),
+	TagItsSessionInfo:           {Name: "its_session_info", Type: TypeOctets, MinLen: 2, MaxLen: 2},
+}
+
+// Def returns the definition of the tag, or false if the tag is not
+// defined by SMPP 3.4.
+func (t Tag) Def() (Def, bool) {
+	d, ok := defs[t]
+	return d, ok
+}
+
+// Validate returns an error if the length of the value v is invalid
+// for the tag. Values of undefined tags are always valid.
+func (t Tag) Validate(v []byte) error {
+	d, ok := defs[t]
+	if !ok || len(v) >= d.MinLen && len(v) <= d.MaxLen {
+		return nil
+	}
+	if d.MinLen == d.MaxLen {
+		return fmt.Errorf("invalid length for tag %s: want %d, have %d", t, d.MinLen, len(v))
+	}
+	return fmt.Errorf("invalid length for tag %s: want %d to %d, have %d", t, d.MinLen, d.MaxLen, len(v))
+}
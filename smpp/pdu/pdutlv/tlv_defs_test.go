@@ -0,0 +1,79 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutlv
+
+import (
+	"testing"
+)
+
+func TestTag_String(t *testing.T) {
+	test := []struct {
+		tag  Tag
+		want string
+	}{
+		{TagMsAvailabilityStatus, "ms_availability_status"},
+		{TagDestinationPort, "destination_port"},
+		{TagScInterfaceVersion, "SC_interface_version"},
+		{0x1400, "1400"},
+	}
+	for _, tc := range test {
+		if v := tc.tag.String(); v != tc.want {
+			t.Fatalf("unexpected name: want %q, have %q", tc.want, v)
+		}
+	}
+}
+
+func TestTag_Validate(t *testing.T) {
+	test := []struct {
+		tag Tag
+		v   []byte
+		ok  bool
+	}{
+		{TagSarMsgRefNum, []byte{0x01, 0x07}, true},
+		{TagSarMsgRefNum, []byte{0x07}, false},
+		{TagQosTimeToLive, []byte{0, 0, 0, 60}, true},
+		{TagQosTimeToLive, []byte{0, 60}, false},
+		{TagReceiptedMessageID, []byte("1A\x00"), true},
+		{TagReceiptedMessageID, nil, false},
+		{TagCallbackNum, []byte{1, 2, 3}, false},
+		{TagMessagePayload, nil, true},
+		{TagAlertOnMessageDelivery, nil, true},
+		{0x1400, []byte("anything"), true},
+	}
+	for _, tc := range test {
+		if err := tc.tag.Validate(tc.v); tc.ok && err != nil {
+			t.Fatal(err)
+		} else if !tc.ok && err == nil {
+			t.Fatalf("unexpected valid %s=%x", tc.tag, tc.v)
+		}
+	}
+}
+
+func TestTLVField_Format(t *testing.T) {
+	test := []struct {
+		f    *Field
+		want string
+	}{
+		{&Field{Tag: TagSarMsgRefNum, Data: []byte{0x01, 0x07}}, "263"},
+		{&Field{Tag: TagMessageStateOption, Data: []byte{2}}, "DELIVERED"},
+		{&Field{Tag: TagMsAvailabilityStatus, Data: []byte{2}}, "UNAVAILABLE"},
+		{&Field{Tag: TagDestNetworkType, Data: []byte{1}}, "GSM"},
+		{&Field{Tag: TagDeliveryFailureReason, Data: []byte{9}}, "UNKNOWN (9)"},
+		{&Field{Tag: TagUssdServiceOp, Data: []byte{17}}, "PSSR_RESPONSE"},
+		{&Field{Tag: TagItsReplyType, Data: []byte{8}}, "CONTINUE"},
+		{&Field{Tag: TagDestBearerType, Data: []byte("hello")}, "hello"},
+		{&Field{Tag: TagSarMsgRefNum, Data: []byte("A")}, "A"},
+	}
+	for _, tc := range test {
+		if v := tc.f.Format(); v != tc.want {
+			t.Fatalf("unexpected format for %s: want %q, have %q", tc.f.Tag, tc.want, v)
+		}
+	}
+	// String returns the raw value.
+	f := &Field{Tag: TagMessageStateOption, Data: []byte{2}}
+	if v := f.String(); v != "\x02" {
+		t.Fatalf("unexpected string for %s: want %q, have %q", f.Tag, "\x02", v)
+	}
+}
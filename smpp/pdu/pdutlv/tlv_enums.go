@@ -0,0 +1,181 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdutlv
+
+import (
+	"fmt"
+)
+
+// enumString returns the name of v in names, or UNKNOWN (v).
+func enumString(names []string, v uint8) string {
+	if int(v) < len(names) && names[v] != "" {
+		return names[v]
+	}
+	return fmt.Sprintf("UNKNOWN (%d)", v)
+}
+
+// AddrSubunit is the value of the dest_addr_subunit and
+// source_addr_subunit TLVs.
+type AddrSubunit byte
+
+func (s AddrSubunit) String() string {
+	return enumString([]string{
+		"UNKNOWN",
+		"MS_DISPLAY",
+		"MOBILE_EQUIPMENT",
+		"SMART_CARD_1",
+		"EXTERNAL_UNIT_1",
+	}, uint8(s))
+}
+
+// NetworkType is the value of the dest_network_type and
+// source_network_type TLVs.
+type NetworkType byte
+
+func (n NetworkType) String() string {
+	return enumString([]string{
+		"UNKNOWN",
+		"GSM",
+		"ANSI-136/TDMA",
+		"IS-95/CDMA",
+		"PDC",
+		"PHS",
+		"iDEN",
+		"AMPS",
+		"PAGING_NETWORK",
+	}, uint8(n))
+}
+
+// BearerType is the value of the dest_bearer_type and
+// source_bearer_type TLVs.
+type BearerType byte
+
+func (b BearerType) String() string {
+	return enumString([]string{
+		"UNKNOWN",
+		"SMS",
+		"CSD",
+		"PACKET_DATA",
+		"USSD",
+		"CDPD",
+		"DATATAC",
+		"FLEX/REFLEX",
+		"CELL_BROADCAST",
+	}, uint8(b))
+}
+
+// PayloadType is the value of the payload_type TLV.
+type PayloadType byte
+
+func (p PayloadType) String() string {
+	return enumString([]string{
+		"DEFAULT",
+		"WCMP",
+	}, uint8(p))
+}
+
+// PrivacyIndicator is the value of the privacy_indicator TLV.
+type PrivacyIndicator byte
+
+func (p PrivacyIndicator) String() string {
+	return enumString([]string{
+		"NOT_RESTRICTED",
+		"RESTRICTED",
+		"CONFIDENTIAL",
+		"SECRET",
+	}, uint8(p))
+}
+
+// LanguageIndicator is the value of the language_indicator TLV.
+type LanguageIndicator byte
+
+func (l LanguageIndicator) String() string {
+	return enumString([]string{
+		"UNSPECIFIED",
+		"ENGLISH",
+		"FRENCH",
+		"SPANISH",
+		"GERMAN",
+		"PORTUGUESE",
+	}, uint8(l))
+}
+
+// DeliveryFailureReason is the value of the delivery_failure_reason TLV.
+type DeliveryFailureReason byte
+
+func (r DeliveryFailureReason) String() string {
+	return enumString([]string{
+		"DESTINATION_UNAVAILABLE",
+		"DESTINATION_ADDRESS_INVALID",
+		"PERMANENT_NETWORK_ERROR",
+		"TEMPORARY_NETWORK_ERROR",
+	}, uint8(r))
+}
+
+// UssdServiceOp is the value of the ussd_service_op TLV.
+type UssdServiceOp byte
+
+func (op UssdServiceOp) String() string {
+	switch op {
+	case 0:
+		return "PSSD_INDICATION"
+	case 1:
+		return "PSSR_INDICATION"
+	case 2:
+		return "USSR_REQUEST"
+	case 3:
+		return "USSN_REQUEST"
+	case 16:
+		return "PSSD_RESPONSE"
+	case 17:
+		return "PSSR_RESPONSE"
+	case 18:
+		return "USSR_CONFIRM"
+	case 19:
+		return "USSN_CONFIRM"
+	default:
+		return fmt.Sprintf("UNKNOWN (%d)", op)
+	}
+}
+
+// DisplayTime is the value of the display_time TLV.
+type DisplayTime byte
+
+func (d DisplayTime) String() string {
+	return enumString([]string{
+		"TEMPORARY",
+		"DEFAULT",
+		"INVOKE",
+	}, uint8(d))
+}
+
+// MsValidity is the value of the ms_validity TLV.
+type MsValidity byte
+
+func (v MsValidity) String() string {
+	return enumString([]string{
+		"STORE_INDEFINITELY",
+		"POWER_DOWN",
+		"SID_BASED_REGISTRATION_AREA",
+		"DISPLAY_ONLY",
+	}, uint8(v))
+}
+
+// ItsReplyType is the value of the its_reply_type TLV.
+type ItsReplyType byte
+
+func (r ItsReplyType) String() string {
+	return enumString([]string{
+		"DIGIT",
+		"NUMBER",
+		"TELEPHONE_NO",
+		"PASSWORD",
+		"CHARACTER_LINE",
+		"MENU",
+		"DATE",
+		"TIME",
+		"CONTINUE",
+	}, uint8(r))
+}
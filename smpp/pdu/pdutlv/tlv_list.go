@@ -11,6 +11,9 @@ import (
 )
 
 // DecodeTLV scans the given byte slice to build a Map from binary data.
+// Values are kept as sent, even if their length is invalid for the tag,
+// so that off-spec PDUs can still be handled. Use Map.Validate or the
+// Map.Get methods to check the values.
 func DecodeTLV(r *bytes.Buffer) (Map, error) {
 	t := make(Map)
 	for r.Len() >= 4 {
@@ -6,7 +6,7 @@ import (
 )
 
 func TestDecodeTLV(t *testing.T) {
-	f := NewTLV(TagDestAddrSubunit, []byte("hello"))
+	f := NewTLV(TagMessagePayload, []byte("hello"))
 	var b bytes.Buffer
 	if err := f.SerializeTo(&b); err != nil {
 		t.Fatalf("serialization failed: %s", err)
@@ -15,9 +15,9 @@ func TestDecodeTLV(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	f, ok := m[TagDestAddrSubunit]
+	f, ok := m[TagMessagePayload]
 	if !ok {
-		t.Fatalf("missing %q key: %#v", TagDestAddrSubunit.Hex(), m)
+		t.Fatalf("missing %q key: %#v", TagMessagePayload.Hex(), m)
 	}
 	v, ok := f.(*Field)
 	if !ok {
@@ -40,4 +40,20 @@ func TestDecodeTLV_Error(t *testing.T) {
 	} else if m != nil {
 		t.Fatalf("expected returned Map to be nil: %#v", m)
 	}
-}
\ No newline at end of file
+}
+func TestDecodeTLV_InvalidLength(t *testing.T) {
+	b := bytes.NewBuffer([]byte{0x02, 0x0C, 0x00, 0x01, 0x07})
+	m, err := DecodeTLV(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := m[TagSarMsgRefNum]; v == nil || !bytes.Equal(v.Bytes(), []byte{0x07}) {
+		t.Fatalf("unexpected sar_msg_ref_num: %#v", v)
+	}
+	if _, err := m.GetUint16(TagSarMsgRefNum); err == nil {
+		t.Fatal("expected invalid length error for sar_msg_ref_num")
+	}
+	if err := m.Validate(); err == nil {
+		t.Fatal("expected invalid length error for sar_msg_ref_num")
+	}
+}
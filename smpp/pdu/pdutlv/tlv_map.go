@@ -5,9 +5,14 @@
 package pdutlv
 
 import (
+	"errors"
 	"fmt"
 )
 
+// ErrNotFound is returned by the Get methods of Map when the tag is
+// not in the map.
+var ErrNotFound = errors.New("TLV field not found")
+
 // Map is a collection of PDU TLV field data indexed by tag.
 type Map map[Tag]Body
 
@@ -15,14 +20,24 @@ type Map map[Tag]Body
 // returns error if the value cannot be converted to type Data.
 //
 // This is a shortcut for m[t] = NewTLV(t, v) converting v properly.
+// Integers of type int are encoded with the size defined for the tag,
+// or in one octet for tags of other types.
 func (m Map) Set(t Tag, v interface{}) error {
 	switch v.(type) {
 	case nil:
 		m[t] = NewTLV(t, nil) // use default value
 	case uint8:
 		m[t] = NewTLV(t, []byte{v.(uint8)})
+	case uint16:
+		m[t] = NewTLV(t, encodeUint(uint32(v.(uint16)), 2))
+	case uint32:
+		m[t] = NewTLV(t, encodeUint(v.(uint32), 4))
 	case int:
-		m[t] = NewTLV(t, []byte{uint8(v.(int))})
+		n := 1
+		if d, ok := defs[t]; ok && d.Type >= TypeUint8 {
+			n = d.MaxLen
+		}
+		m[t] = NewTLV(t, encodeUint(uint32(v.(int)), n))
 	case string:
 		m[t] = NewTLV(t, []byte(v.(string)))
 	case String:
@@ -42,3 +57,118 @@ func (m Map) Set(t Tag, v interface{}) error {
 	}
 	return nil
 }
+
+// SetUint8 sets the tag to the 1 octet integer v. It returns an error
+// if the tag is defined with a different length.
+func (m Map) SetUint8(t Tag, v uint8) error {
+	return m.setValidated(t, []byte{v})
+}
+
+// SetUint16 sets the tag to the 2 octet integer v. It returns an error
+// if the tag is defined with a different length.
+func (m Map) SetUint16(t Tag, v uint16) error {
+	return m.setValidated(t, encodeUint(uint32(v), 2))
+}
+
+// SetUint32 sets the tag to the 4 octet integer v. It returns an error
+// if the tag is defined with a different length.
+func (m Map) SetUint32(t Tag, v uint32) error {
+	return m.setValidated(t, encodeUint(v, 4))
+}
+
+// setValidated sets the tag to the value b, if valid for the tag.
+func (m Map) setValidated(t Tag, b []byte) error {
+	if err := t.Validate(b); err != nil {
+		return err
+	}
+	m[t] = NewTLV(t, b)
+	return nil
+}
+
+// GetUint8 returns the value of the tag as a 1 octet integer.
+func (m Map) GetUint8(t Tag) (uint8, error) {
+	b, err := m.get(t, 1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// GetUint16 returns the value of the tag as a 2 octet integer.
+func (m Map) GetUint16(t Tag) (uint16, error) {
+	b, err := m.get(t, 2)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(decodeUint(b)), nil
+}
+
+// GetUint32 returns the value of the tag as a 4 octet integer.
+func (m Map) GetUint32(t Tag) (uint32, error) {
+	b, err := m.get(t, 4)
+	if err != nil {
+		return 0, err
+	}
+	return decodeUint(b), nil
+}
+
+// GetString returns the value of the tag as text, without null
+// terminator. It returns an error if the length of the value is
+// invalid for the tag.
+func (m Map) GetString(t Tag) (string, error) {
+	b, err := m.get(t, -1)
+	if err != nil {
+		return "", err
+	}
+	if l := len(b); l > 0 && b[l-1] == 0x00 {
+		b = b[:l-1]
+	}
+	return string(b), nil
+}
+
+// get returns the value of the tag, validated for the tag and with
+// length n, if not negative.
+func (m Map) get(t Tag, n int) ([]byte, error) {
+	f, ok := m[t]
+	if !ok || f == nil {
+		return nil, ErrNotFound
+	}
+	b := f.Bytes()
+	if err := t.Validate(b); err != nil {
+		return nil, err
+	}
+	if n >= 0 && len(b) != n {
+		return nil, fmt.Errorf("invalid length for tag %s: want %d, have %d", t, n, len(b))
+	}
+	return b, nil
+}
+
+// Validate returns an error if the length of any value in the map is
+// invalid for its tag.
+func (m Map) Validate() error {
+	for t, f := range m {
+		if err := t.Validate(f.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeUint encodes v as a big endian integer of n octets.
+func encodeUint(v uint32, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = uint8(v)
+		v >>= 8
+	}
+	return b
+}
+
+// decodeUint decodes b as a big endian integer.
+func decodeUint(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
@@ -5,6 +5,7 @@
 package pdutlv
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -34,4 +35,72 @@ func TestMapSet(t *testing.T) {
 			t.Fatalf("unexpected set of %q=%#v", el.k, el.v)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestMapSetInt(t *testing.T) {
+	m := make(Map)
+	test := []struct {
+		k    Tag
+		v    interface{}
+		want []byte
+	}{
+		{TagSarMsgRefNum, 263, []byte{0x01, 0x07}},
+		{TagSarMsgRefNum, uint16(263), []byte{0x01, 0x07}},
+		{TagQosTimeToLive, 60, []byte{0, 0, 0, 60}},
+		{TagQosTimeToLive, uint32(60), []byte{0, 0, 0, 60}},
+		{TagSarTotalSegments, 3, []byte{3}},
+		{TagMessagePayload, 3, []byte{3}},
+	}
+	for _, tc := range test {
+		if err := m.Set(tc.k, tc.v); err != nil {
+			t.Fatal(err)
+		}
+		if v := m[tc.k].Bytes(); !bytes.Equal(v, tc.want) {
+			t.Fatalf("unexpected %s=%#v: want %x, have %x", tc.k, tc.v, tc.want, v)
+		}
+	}
+}
+
+func TestMapTyped(t *testing.T) {
+	m := make(Map)
+	if err := m.SetUint16(TagSourcePort, 9200); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetUint8(TagSarTotalSegments, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetUint32(TagQosTimeToLive, 3600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetUint8(TagSarMsgRefNum, 1); err == nil {
+		t.Fatal("unexpected set of 1 octet sar_msg_ref_num")
+	}
+	if v, err := m.GetUint16(TagSourcePort); err != nil || v != 9200 {
+		t.Fatalf("unexpected source_port: %d, %v", v, err)
+	}
+	if v, err := m.GetUint8(TagSarTotalSegments); err != nil || v != 2 {
+		t.Fatalf("unexpected sar_total_segments: %d, %v", v, err)
+	}
+	if v, err := m.GetUint32(TagQosTimeToLive); err != nil || v != 3600 {
+		t.Fatalf("unexpected qos_time_to_live: %d, %v", v, err)
+	}
+	if _, err := m.GetUint8(TagSourcePort); err == nil {
+		t.Fatal("unexpected 1 octet source_port")
+	}
+	if _, err := m.GetUint16(TagDestinationPort); err != ErrNotFound {
+		t.Fatalf("unexpected error: want ErrNotFound, have %v", err)
+	}
+	m.Set(TagReceiptedMessageID, CString("1A"))
+	if v, err := m.GetString(TagReceiptedMessageID); err != nil || v != "1A" {
+		t.Fatalf("unexpected receipted_message_id: %q, %v", v, err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	m.Set(TagSarSegmentSeqnum, []byte{0, 1})
+	if _, err := m.GetUint8(TagSarSegmentSeqnum); err == nil {
+		t.Fatal("unexpected valid sar_segment_seqnum")
+	}
+	if err := m.Validate(); err == nil {
+		t.Fatal("unexpected valid map")
+	}
+}
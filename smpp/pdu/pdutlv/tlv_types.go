@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"strconv"
 )
 
 // Fields is a map of tagged TLV fields
@@ -56,6 +57,7 @@ const (
 	TagLanguageIndicator        Tag = 0x020D
 	TagSarTotalSegments         Tag = 0x020E
 	TagSarSegmentSeqnum         Tag = 0x020F
+	TagScInterfaceVersion       Tag = 0x0210
 	TagCallbackNumPresInd       Tag = 0x0302
 	TagCallbackNumAtag          Tag = 0x0303
 	TagNumberOfMessages         Tag = 0x0304
@@ -77,97 +79,13 @@ const (
 	TagItsSessionInfo           Tag = 0x1383
 )
 
+// String returns the name of the tag, or its hexadecimal
+// representation if the tag is not defined by SMPP 3.4.
 func (t Tag) String() string {
-	switch t {
-	case TagDestAddrSubunit:
-		return "dest_addr_subunit"
-	case TagDestNetworkType:
-		return "dest_network_type"
-	case TagDestBearerType:
-		return "dest_bearer_type"
-	case TagDestTelematicsID:
-		return "dest_telematics_id"
-	case TagSourceAddrSubunit:
-		return "source_addr_subunit"
-	case TagSourceNetworkType:
-		return "source_network_type"
-	case TagSourceBearerType:
-		return "source_bearer_type"
-	case TagSourceTelematicsID:
-		return "source_telematics_id"
-	case TagQosTimeToLive:
-		return "qos_time_to_live"
-	case TagPayloadType:
-		return "payload_type"
-	case TagAdditionalStatusInfoText:
-		return "additional_status_info_text"
-	case TagReceiptedMessageID:
-		return "receipted_message_id"
-	case TagMsMsgWaitFacilities:
-		return "ms_msg_wait_facilities"
-	case TagPrivacyIndicator:
-		return "privacy_indicator"
-	case TagSourceSubaddress:
-		return "source_subaddress"
-	case TagDestSubaddress:
-		return "dest_subaddress"
-	case TagUserMessageReference:
-		return "user_message_reference"
-	case TagUserResponseCode:
-		return "user_response_code"
-	case TagSourcePort:
-		return "source_port"
-	case TagDestinationPort:
-		return "destination_port "
-	case TagSarMsgRefNum:
-		return "sar_msg_ref_num"
-	case TagLanguageIndicator:
-		return "language_indicator"
-	case TagSarTotalSegments:
-		return "sar_total_segments"
-	case TagSarSegmentSeqnum:
-		return "sar_segment_seqnum"
-	case TagCallbackNumPresInd:
-		return "callback_num_pres_ind"
-	case TagCallbackNumAtag:
-		return "callback_num_atag"
-	case TagNumberOfMessages:
-		return "number_of_messages"
-	case TagCallbackNum:
-		return "callback_num"
-	case TagDpfResult:
-		return "dpf_result"
-	case TagSetDpf:
-		return "set_dpf"
-	case TagMsAvailabilityStatus:
-		return "ms_availablity_status"
-	case TagNetworkErrorCode:
-		return "network_error_code"
-	case TagMessagePayload:
-		return "message_payload"
-	case TagDeliveryFailureReason:
-		return "delivery_failure_reason"
-	case TagMoreMessagesToSend:
-		return "more_messages_to_send"
-	case TagMessageStateOption:
-		return "message_state"
-	case TagUssdServiceOp:
-		return "ussd_service_op"
-	case TagDisplayTime:
-		return "display_time"
-	case TagSmsSignal:
-		return "sms_signal"
-	case TagMsValidity:
-		return "ms_validity"
-	case TagAlertOnMessageDelivery:
-		return "alert_on_message_delivery"
-	case TagItsReplyType:
-		return "its_reply_type"
-	case TagItsSessionInfo:
-		return "its_session_info"
-	default:
-		return t.Hex()
+	if d, ok := defs[t]; ok {
+		return d.Name
 	}
+	return t.Hex()
 }
 
 // Field is a PDU Tag-Length-Value (TLV) field
@@ -194,6 +112,21 @@ func (t *Field) String() string {
 	return string(t.Data)
 }
 
+// Format returns the value of the field for display. Values of integer
+// tags are returned in decimal, or as the name of the value for enum
+// tags such as message_state. Other values, and integers with invalid
+// length, are returned as String does.
+func (t *Field) Format() string {
+	if d, ok := defs[t.Tag]; ok && d.Type >= TypeUint8 && t.Tag.Validate(t.Data) == nil {
+		v := decodeUint(t.Data)
+		if d.enum != nil {
+			return d.enum(v).String()
+		}
+		return strconv.FormatUint(uint64(v), 10)
+	}
+	return t.String()
+}
+
 // Bytes implements the Data interface.
 func (t *Field) Bytes() []byte {
 	return t.Data
@@ -238,3 +171,19 @@ func (ms MessageState) String() string {
 		return fmt.Sprintf("UNKNOWN (%d)", ms)
 	}
 }
+
+// MsAvailabilityStatus is the value of the ms_availability_status TLV.
+type MsAvailabilityStatus byte
+
+func (s MsAvailabilityStatus) String() string {
+	switch s {
+	case 0:
+		return "AVAILABLE"
+	case 1:
+		return "DENIED"
+	case 2:
+		return "UNAVAILABLE"
+	default:
+		return fmt.Sprintf("UNKNOWN (%d)", s)
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package pduudh provides the user data header of short messages and
+// its information elements, as defined by 3GPP TS 23.040.
+//
+// The user data header is sent at the start of the short_message field
+// when the UDHI flag (0x40) of esm_class is set.
+package pduudh
@@ -0,0 +1,362 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pduudh
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+)
+
+// IEI is an information element identifier.
+type IEI uint8
+
+// Supported information element identifiers.
+const (
+	IEIConcat8              IEI = 0x00 // Concatenated short messages, 8-bit reference number
+	IEISpecialSMS           IEI = 0x01 // Special SMS message indication
+	IEIPort8                IEI = 0x04 // Application port addressing scheme, 8-bit address
+	IEIPort16               IEI = 0x05 // Application port addressing scheme, 16-bit address
+	IEIConcat16             IEI = 0x08 // Concatenated short messages, 16-bit reference number
+	IEITextFormatting       IEI = 0x0A // EMS text formatting
+	IEIPredefinedSound      IEI = 0x0B // EMS predefined sound
+	IEIUserDefinedSound     IEI = 0x0C // EMS user defined sound (iMelody)
+	IEIPredefinedAnimation  IEI = 0x0D // EMS predefined animation
+	IEILargeAnimation       IEI = 0x0E // EMS large animation, 4 frames of 16x16 pixels
+	IEISmallAnimation       IEI = 0x0F // EMS small animation, 4 frames of 8x8 pixels
+	IEILargePicture         IEI = 0x10 // EMS large picture, 32x32 pixels
+	IEISmallPicture         IEI = 0x11 // EMS small picture, 16x16 pixels
+	IEIVariablePicture      IEI = 0x12 // EMS variable picture
+	IEIUserPromptIndicator  IEI = 0x13 // EMS user prompt indicator
+	IEINationalSingleShift  IEI = 0x24 // National language single shift
+	IEINationalLockingShift IEI = 0x25 // National language locking shift
+)
+
+// String implements the fmt.Stringer interface.
+func (i IEI) String() string {
+	return fmt.Sprintf("%#02x", uint8(i))
+}
+
+// ieLen is the data length of the information elements of fixed length.
+var ieLen = map[IEI]int{
+	IEIConcat8:              3,
+	IEISpecialSMS:           2,
+	IEIPort8:                2,
+	IEIPort16:               4,
+	IEIConcat16:             4,
+	IEIPredefinedSound:      2,
+	IEIPredefinedAnimation:  2,
+	IEILargeAnimation:       1 + 128,
+	IEISmallAnimation:       1 + 32,
+	IEILargePicture:         1 + 128,
+	IEISmallPicture:         1 + 32,
+	IEIUserPromptIndicator:  1,
+	IEINationalSingleShift:  1,
+	IEINationalLockingShift: 1,
+}
+
+// ieMinLen is the minimum data length of the information elements of
+// variable length.
+var ieMinLen = map[IEI]int{
+	IEITextFormatting:   3,
+	IEIUserDefinedSound: 1,
+	IEIVariablePicture:  3,
+}
+
+// newIE returns the typed information element with the given
+// identifier and data of valid length.
+func newIE(iei IEI, data []byte) IE {
+	switch iei {
+	case IEIConcat8:
+		return Concat8{Ref: data[0], Total: data[1], Seq: data[2]}
+	case IEISpecialSMS:
+		return SpecialSMS{
+			Store:      data[0]&0x80 != 0,
+			Indication: pdutext.Indication(data[0] & 0x03),
+			Extended:   data[0] >> 2 & 0x07,
+			Profile:    data[0] >> 5 & 0x03,
+			Count:      data[1],
+		}
+	case IEIPort8:
+		return Port8{Dst: data[0], Src: data[1]}
+	case IEIPort16:
+		return Port16{
+			Dst: binary.BigEndian.Uint16(data),
+			Src: binary.BigEndian.Uint16(data[2:]),
+		}
+	case IEIConcat16:
+		return Concat16{
+			Ref:   binary.BigEndian.Uint16(data),
+			Total: data[2],
+			Seq:   data[3],
+		}
+	case IEITextFormatting:
+		ie := TextFormatting{Position: data[0], Length: data[1], Mode: data[2]}
+		if len(data) > 3 {
+			ie.Color, ie.HasColor = data[3], true
+		}
+		return ie
+	case IEIPredefinedSound:
+		return PredefinedSound{Position: data[0], Number: data[1]}
+	case IEIUserDefinedSound:
+		return UserDefinedSound{Position: data[0], Melody: data[1:]}
+	case IEIPredefinedAnimation:
+		return PredefinedAnimation{Position: data[0], Number: data[1]}
+	case IEILargeAnimation, IEISmallAnimation:
+		return Animation{Position: data[0], Large: iei == IEILargeAnimation, Bitmap: data[1:]}
+	case IEILargePicture, IEISmallPicture:
+		return Picture{Position: data[0], Large: iei == IEILargePicture, Bitmap: data[1:]}
+	case IEIVariablePicture:
+		return VariablePicture{Position: data[0], Width: data[1], Height: data[2], Bitmap: data[3:]}
+	case IEIUserPromptIndicator:
+		return UserPromptIndicator{Objects: data[0]}
+	case IEINationalSingleShift:
+		return NationalSingleShift{Language: encoding.Language(data[0])}
+	case IEINationalLockingShift:
+		return NationalLockingShift{Language: encoding.Language(data[0])}
+	}
+	return Raw{ID: iei, Content: data}
+}
+
+// Concat8 identifies a part of a long message with an 8-bit reference
+// number.
+type Concat8 struct {
+	Ref   uint8 // Reference number, the same in all parts.
+	Total uint8 // Total number of parts.
+	Seq   uint8 // Part number, starting at 1.
+}
+
+// IEI implements the IE interface.
+func (ie Concat8) IEI() IEI { return IEIConcat8 }
+
+// Data implements the IE interface.
+func (ie Concat8) Data() []byte { return []byte{ie.Ref, ie.Total, ie.Seq} }
+
+// Concat16 identifies a part of a long message with a 16-bit reference
+// number.
+type Concat16 struct {
+	Ref   uint16 // Reference number, the same in all parts.
+	Total uint8  // Total number of parts.
+	Seq   uint8  // Part number, starting at 1.
+}
+
+// IEI implements the IE interface.
+func (ie Concat16) IEI() IEI { return IEIConcat16 }
+
+// Data implements the IE interface.
+func (ie Concat16) Data() []byte {
+	return []byte{uint8(ie.Ref >> 8), uint8(ie.Ref), ie.Total, ie.Seq}
+}
+
+// SpecialSMS indicates messages waiting, such as voicemail.
+type SpecialSMS struct {
+	Store      bool               // Store the message, or else discard it after updating the indication.
+	Indication pdutext.Indication // Basic indication type.
+	Extended   uint8              // Extended message type, 3 bits: 0 for none, 1 for video.
+	Profile    uint8              // Multiple subscriber profile, 2 bits.
+	Count      uint8              // Number of messages waiting, 0 to clear the indication.
+}
+
+// IEI implements the IE interface.
+func (ie SpecialSMS) IEI() IEI { return IEISpecialSMS }
+
+// Data implements the IE interface.
+func (ie SpecialSMS) Data() []byte {
+	b := uint8(ie.Indication)&0x03 | (ie.Extended&0x07)<<2 | (ie.Profile&0x03)<<5
+	if ie.Store {
+		b |= 0x80
+	}
+	return []byte{b, ie.Count}
+}
+
+// Port8 addresses a message to an application port, with 8-bit port
+// numbers.
+type Port8 struct {
+	Dst uint8 // Destination port.
+	Src uint8 // Originator port.
+}
+
+// IEI implements the IE interface.
+func (ie Port8) IEI() IEI { return IEIPort8 }
+
+// Data implements the IE interface.
+func (ie Port8) Data() []byte { return []byte{ie.Dst, ie.Src} }
+
+// Port16 addresses a message to an application port, with 16-bit port
+// numbers. WAP push messages are sent to port 2948 from port 9200.
+type Port16 struct {
+	Dst uint16 // Destination port.
+	Src uint16 // Originator port.
+}
+
+// IEI implements the IE interface.
+func (ie Port16) IEI() IEI { return IEIPort16 }
+
+// Data implements the IE interface.
+func (ie Port16) Data() []byte {
+	return []byte{uint8(ie.Dst >> 8), uint8(ie.Dst), uint8(ie.Src >> 8), uint8(ie.Src)}
+}
+
+// TextFormatting is an EMS element that formats Length characters of
+// the text from Position.
+type TextFormatting struct {
+	Position uint8
+	Length   uint8
+	Mode     uint8 // Alignment, font size, bold, italic, underlined and strikethrough bits.
+	Color    uint8 // Foreground and background colors, sent if HasColor is set.
+	HasColor bool
+}
+
+// IEI implements the IE interface.
+func (ie TextFormatting) IEI() IEI { return IEITextFormatting }
+
+// Data implements the IE interface.
+func (ie TextFormatting) Data() []byte {
+	if ie.HasColor {
+		return []byte{ie.Position, ie.Length, ie.Mode, ie.Color}
+	}
+	return []byte{ie.Position, ie.Length, ie.Mode}
+}
+
+// PredefinedSound is an EMS element that plays a predefined sound at
+// Position of the text.
+type PredefinedSound struct {
+	Position uint8
+	Number   uint8
+}
+
+// IEI implements the IE interface.
+func (ie PredefinedSound) IEI() IEI { return IEIPredefinedSound }
+
+// Data implements the IE interface.
+func (ie PredefinedSound) Data() []byte { return []byte{ie.Position, ie.Number} }
+
+// UserDefinedSound is an EMS element that plays a melody in iMelody
+// format at Position of the text.
+type UserDefinedSound struct {
+	Position uint8
+	Melody   []byte
+}
+
+// IEI implements the IE interface.
+func (ie UserDefinedSound) IEI() IEI { return IEIUserDefinedSound }
+
+// Data implements the IE interface.
+func (ie UserDefinedSound) Data() []byte {
+	return append([]byte{ie.Position}, ie.Melody...)
+}
+
+// PredefinedAnimation is an EMS element that shows a predefined
+// animation at Position of the text.
+type PredefinedAnimation struct {
+	Position uint8
+	Number   uint8
+}
+
+// IEI implements the IE interface.
+func (ie PredefinedAnimation) IEI() IEI { return IEIPredefinedAnimation }
+
+// Data implements the IE interface.
+func (ie PredefinedAnimation) Data() []byte { return []byte{ie.Position, ie.Number} }
+
+// Animation is an EMS element that shows an animation of 4 frames at
+// Position of the text. Bitmap holds 128 octets for large animations,
+// or else 32 octets.
+type Animation struct {
+	Position uint8
+	Large    bool
+	Bitmap   []byte
+}
+
+// IEI implements the IE interface.
+func (ie Animation) IEI() IEI {
+	if ie.Large {
+		return IEILargeAnimation
+	}
+	return IEISmallAnimation
+}
+
+// Data implements the IE interface.
+func (ie Animation) Data() []byte {
+	return append([]byte{ie.Position}, ie.Bitmap...)
+}
+
+// Picture is an EMS element that shows a black and white picture at
+// Position of the text. Bitmap holds 128 octets for large pictures,
+// or else 32 octets.
+type Picture struct {
+	Position uint8
+	Large    bool
+	Bitmap   []byte
+}
+
+// IEI implements the IE interface.
+func (ie Picture) IEI() IEI {
+	if ie.Large {
+		return IEILargePicture
+	}
+	return IEISmallPicture
+}
+
+// Data implements the IE interface.
+func (ie Picture) Data() []byte {
+	return append([]byte{ie.Position}, ie.Bitmap...)
+}
+
+// VariablePicture is an EMS element that shows a black and white
+// picture of any size at Position of the text.
+type VariablePicture struct {
+	Position uint8
+	Width    uint8 // In units of 8 pixels.
+	Height   uint8 // In pixels.
+	Bitmap   []byte
+}
+
+// IEI implements the IE interface.
+func (ie VariablePicture) IEI() IEI { return IEIVariablePicture }
+
+// Data implements the IE interface.
+func (ie VariablePicture) Data() []byte {
+	return append([]byte{ie.Position, ie.Width, ie.Height}, ie.Bitmap...)
+}
+
+// UserPromptIndicator is an EMS element that indicates that the
+// following Objects elements can be used as a user prompt, such as a
+// ring tone.
+type UserPromptIndicator struct {
+	Objects uint8
+}
+
+// IEI implements the IE interface.
+func (ie UserPromptIndicator) IEI() IEI { return IEIUserPromptIndicator }
+
+// Data implements the IE interface.
+func (ie UserPromptIndicator) Data() []byte { return []byte{ie.Objects} }
+
+// NationalSingleShift selects the national language single shift table
+// of GSM 7-bit text.
+type NationalSingleShift struct {
+	Language encoding.Language
+}
+
+// IEI implements the IE interface.
+func (ie NationalSingleShift) IEI() IEI { return IEINationalSingleShift }
+
+// Data implements the IE interface.
+func (ie NationalSingleShift) Data() []byte { return []byte{uint8(ie.Language)} }
+
+// NationalLockingShift selects the national language locking shift
+// table of GSM 7-bit text.
+type NationalLockingShift struct {
+	Language encoding.Language
+}
+
+// IEI implements the IE interface.
+func (ie NationalLockingShift) IEI() IEI { return IEINationalLockingShift }
+
+// Data implements the IE interface.
+func (ie NationalLockingShift) Data() []byte { return []byte{uint8(ie.Language)} }
@@ -0,0 +1,129 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pduudh
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+)
+
+// ErrInvalidUDH is returned by Unmarshal and Parse when the user data
+// header is malformed.
+var ErrInvalidUDH = errors.New("invalid user data header")
+
+// IE is an information element of the user data header.
+type IE interface {
+	// IEI returns the information element identifier.
+	IEI() IEI
+
+	// Data returns the information element data, without the
+	// identifier and length octets.
+	Data() []byte
+}
+
+// UDH is a user data header: a list of information elements.
+type UDH []IE
+
+// Len returns the length of the encoded header, including the header
+// length octet, or 0 for an empty header.
+func (h UDH) Len() int {
+	if len(h) == 0 {
+		return 0
+	}
+	l := 1
+	for _, ie := range h {
+		l += 2 + len(ie.Data())
+	}
+	return l
+}
+
+// Marshal encodes the header, starting with the header length octet.
+// It returns nil for an empty header, and an error if the header does
+// not fit in a short message.
+func (h UDH) Marshal() ([]byte, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	if l := h.Len(); l > pdutext.MaxLen {
+		return nil, fmt.Errorf("user data header too large: %d > %d", l, pdutext.MaxLen)
+	}
+	b := make([]byte, 1, h.Len())
+	for _, ie := range h {
+		data := ie.Data()
+		b = append(b, uint8(ie.IEI()), uint8(len(data)))
+		b = append(b, data...)
+	}
+	b[0] = uint8(len(b) - 1)
+	return b, nil
+}
+
+// Unmarshal decodes the header at the start of the user data ud, and
+// returns it with the data that follows it.
+func Unmarshal(ud []byte) (UDH, []byte, error) {
+	if len(ud) == 0 || int(ud[0])+1 > len(ud) {
+		return nil, nil, ErrInvalidUDH
+	}
+	h, err := Parse(ud[1 : int(ud[0])+1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, ud[1+int(ud[0]):], nil
+}
+
+// Parse decodes the information elements in b, a header without the
+// header length octet.
+func Parse(b []byte) (UDH, error) {
+	var h UDH
+	for len(b) > 0 {
+		if len(b) < 2 || int(b[1])+2 > len(b) {
+			return nil, ErrInvalidUDH
+		}
+		ie, err := NewIE(IEI(b[0]), b[2:2+int(b[1])])
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, ie)
+		b = b[2+int(b[1]):]
+	}
+	return h, nil
+}
+
+// NewIE returns the typed information element with the given
+// identifier and data, or Raw for unsupported identifiers. It returns
+// an error if the length of data is invalid for the identifier.
+func NewIE(iei IEI, data []byte) (IE, error) {
+	if n, ok := ieLen[iei]; ok && len(data) != n {
+		return nil, fmt.Errorf("invalid length of information element %s: %d", iei, len(data))
+	}
+	if n, ok := ieMinLen[iei]; ok && len(data) < n {
+		return nil, fmt.Errorf("invalid length of information element %s: %d", iei, len(data))
+	}
+	return newIE(iei, data), nil
+}
+
+// Find returns the first information element of h with the given
+// identifier, or nil.
+func (h UDH) Find(iei IEI) IE {
+	for _, ie := range h {
+		if ie.IEI() == iei {
+			return ie
+		}
+	}
+	return nil
+}
+
+// Raw is an information element not interpreted by this package.
+type Raw struct {
+	ID      IEI
+	Content []byte
+}
+
+// IEI implements the IE interface.
+func (ie Raw) IEI() IEI { return ie.ID }
+
+// Data implements the IE interface.
+func (ie Raw) Data() []byte { return ie.Content }
@@ -0,0 +1,129 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pduudh
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fiorix/go-smpp/smpp/encoding"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+)
+
+func TestMarshal(t *testing.T) {
+	picture := bytes.Repeat([]byte{0xAA}, 32)
+	test := []struct {
+		ie   IE
+		want []byte
+	}{
+		{Concat8{Ref: 0x0A, Total: 3, Seq: 1}, []byte{0x00, 3, 0x0A, 3, 1}},
+		{Concat16{Ref: 0x0107, Total: 2, Seq: 2}, []byte{0x08, 4, 0x01, 0x07, 2, 2}},
+		{SpecialSMS{Store: true, Indication: pdutext.VoicemailWaiting, Count: 3}, []byte{0x01, 2, 0x80, 3}},
+		{SpecialSMS{Indication: pdutext.EmailWaiting, Extended: 1, Profile: 3}, []byte{0x01, 2, 0x66, 0}},
+		{Port8{Dst: 0xF5, Src: 0xF6}, []byte{0x04, 2, 0xF5, 0xF6}},
+		{Port16{Dst: 2948, Src: 9200}, []byte{0x05, 4, 0x0B, 0x84, 0x23, 0xF0}},
+		{TextFormatting{Position: 1, Length: 4, Mode: 0x10}, []byte{0x0A, 3, 1, 4, 0x10}},
+		{TextFormatting{Position: 1, Length: 4, Mode: 0x10, Color: 0x21, HasColor: true}, []byte{0x0A, 4, 1, 4, 0x10, 0x21}},
+		{PredefinedSound{Position: 5, Number: 2}, []byte{0x0B, 2, 5, 2}},
+		{UserDefinedSound{Position: 0, Melody: []byte("BEGIN")}, []byte{0x0C, 6, 0, 'B', 'E', 'G', 'I', 'N'}},
+		{PredefinedAnimation{Position: 5, Number: 9}, []byte{0x0D, 2, 5, 9}},
+		{Picture{Position: 2, Bitmap: picture}, append([]byte{0x11, 33, 2}, picture...)},
+		{Animation{Position: 2, Bitmap: picture}, append([]byte{0x0F, 33, 2}, picture...)},
+		{VariablePicture{Position: 0, Width: 1, Height: 2, Bitmap: []byte{0xFF, 0x81}}, []byte{0x12, 5, 0, 1, 2, 0xFF, 0x81}},
+		{UserPromptIndicator{Objects: 1}, []byte{0x13, 1, 1}},
+		{NationalSingleShift{Language: encoding.Turkish}, []byte{0x24, 1, 1}},
+		{NationalLockingShift{Language: encoding.Portuguese}, []byte{0x25, 1, 3}},
+		{Raw{ID: 0x70, Content: []byte{1, 2}}, []byte{0x70, 2, 1, 2}},
+	}
+	for _, tc := range test {
+		b, err := UDH{tc.ie}.Marshal()
+		if err != nil {
+			t.Fatalf("%#v: %v", tc.ie, err)
+		}
+		want := append([]byte{uint8(len(tc.want))}, tc.want...)
+		if !bytes.Equal(b, want) {
+			t.Fatalf("unexpected data for %#v:\nwant: %x\nhave: %x", tc.ie, want, b)
+		}
+		h, ud, err := Unmarshal(append(b, "text"...))
+		if err != nil {
+			t.Fatalf("%#v: %v", tc.ie, err)
+		}
+		if string(ud) != "text" {
+			t.Fatalf("unexpected user data for %#v: %q", tc.ie, ud)
+		}
+		if !reflect.DeepEqual(h, UDH{tc.ie}) {
+			t.Fatalf("unexpected header:\nwant: %#v\nhave: %#v", UDH{tc.ie}, h)
+		}
+	}
+}
+
+func TestMarshalEmpty(t *testing.T) {
+	var h UDH
+	if b, err := h.Marshal(); b != nil || err != nil {
+		t.Fatalf("unexpected empty header: %x, %v", b, err)
+	}
+	if l := h.Len(); l != 0 {
+		t.Fatalf("unexpected length: %d", l)
+	}
+}
+
+func TestMarshalTooLarge(t *testing.T) {
+	h := UDH{
+		UserDefinedSound{Melody: make([]byte, 100)},
+		Picture{Bitmap: make([]byte, 32)},
+	}
+	if l := h.Len(); l != 1+103+35 {
+		t.Fatalf("unexpected length: %d", l)
+	}
+	h = append(h, Port8{})
+	if _, err := h.Marshal(); err == nil {
+		t.Fatal("want error for large header, have nil")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	ud := []byte{
+		0x0B,                               // header length
+		0x05, 0x04, 0x0B, 0x84, 0x23, 0xF0, // port 2948 from 9200
+		0x00, 0x03, 0x2A, 0x02, 0x01, // part 1 of 2, reference 42
+		0x01, 0x02, // user data
+	}
+	h, data, err := Unmarshal(ud)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UDH{Port16{Dst: 2948, Src: 9200}, Concat8{Ref: 42, Total: 2, Seq: 1}}
+	if !reflect.DeepEqual(h, want) {
+		t.Fatalf("unexpected header:\nwant: %#v\nhave: %#v", want, h)
+	}
+	if !bytes.Equal(data, []byte{1, 2}) {
+		t.Fatalf("unexpected data: %x", data)
+	}
+	if ie := h.Find(IEIConcat8); ie != want[1] {
+		t.Fatalf("unexpected element: %#v", ie)
+	}
+	if ie := h.Find(IEIConcat16); ie != nil {
+		t.Fatalf("unexpected element: %#v", ie)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	test := [][]byte{
+		{},
+		{0x05, 0x00, 0x03, 0x01},       // header longer than data
+		{0x04, 0x00, 0x03, 0x01, 0x02}, // element longer than header
+		{0x04, 0x00, 0x02, 0x01, 0x02}, // invalid concatenation length
+		{0x03, 0x0A, 0x01, 0x01},       // short text formatting
+	}
+	for _, ud := range test {
+		if h, _, err := Unmarshal(ud); err == nil {
+			t.Fatalf("want error for %x, have %#v", ud, h)
+		}
+	}
+	if _, err := Parse([]byte{0x24, 0x01, 0x01, 0x00}); err != ErrInvalidUDH {
+		t.Fatalf("want ErrInvalidUDH, have %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdu
+
+import (
+	"github.com/fiorix/go-smpp/smpp/encoding"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/smpp/pdu/pduudh"
+)
+
+// Text decodes the short message of p, for PDUs such as SubmitSM and
+// DeliverSM, according to their data_coding field, using
+// pdutext.DecodeSM. The message_payload TLV is decoded instead when
+// the short message is empty.
+//
+// When the UDHI flag of esm_class is set, the user data header is
+// skipped, and its national language shift tables are used to decode
+// GSM 7-bit text. It returns an empty string for PDUs without text.
+func Text(p Body) (string, error) {
+	fields, tlvs := p.Fields(), p.TLVFields()
+	var c pdutext.DataCoding
+	if f := fields[pdufield.DataCoding]; f != nil && len(f.Bytes()) > 0 {
+		c = pdutext.DataCoding(f.Bytes()[0])
+	}
+	var data []byte
+	if f, ok := fields[pdufield.ShortMessage].(*pdufield.SM); ok {
+		data = f.Data
+	}
+	if len(data) == 0 {
+		if f := tlvs[pdutlv.TagMessagePayload]; f != nil {
+			data = f.Bytes()
+		}
+	}
+	var udh pduudh.UDH
+	if f, ok := fields[pdufield.GSMUserData].(*pdufield.UDHList); ok {
+		for _, u := range f.Data { // already split from the short message
+			if ie, err := pduudh.NewIE(pduudh.IEI(u.IEI.Data), u.IEData.Data); err == nil {
+				udh = append(udh, ie)
+			}
+		}
+	} else if f := fields[pdufield.ESMClass]; f != nil && len(f.Bytes()) > 0 && f.Bytes()[0]&0x40 != 0 {
+		var err error
+		if udh, data, err = pduudh.Unmarshal(data); err != nil {
+			return "", err
+		}
+	}
+	var locking, single encoding.Language
+	if ie, ok := udh.Find(pduudh.IEINationalLockingShift).(pduudh.NationalLockingShift); ok {
+		locking = ie.Language
+	}
+	if ie, ok := udh.Find(pduudh.IEINationalSingleShift).(pduudh.NationalSingleShift); ok {
+		single = ie.Language
+	}
+	return pdutext.DecodeSMLanguage(c, data, locking, single)
+}
+
+// Text decodes the short message of the PDU. See the Text function.
+func (pdu *Codec) Text() (string, error) {
+	return Text(pdu)
+}
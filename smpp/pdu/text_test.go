@@ -0,0 +1,76 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pdu
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+)
+
+func TestText(t *testing.T) {
+	test := []struct {
+		name    string
+		coding  uint8
+		esm     uint8
+		sm      string
+		payload string
+		want    string
+	}{
+		{"gsm7", 0x00, 0, "Hello \x1b\x65", "", "Hello €"},
+		{"ucs2", 0x08, 0, "\x00m\x00o\x00\xe7\x00o", "", "moço"},
+		{"flash ucs2", 0x18, 0, "\x00m\x00o\x00\xe7\x00o", "", "moço"},
+		{"udh", 0x08, 0x40, "\x05\x00\x03\x01\x02\x01\x00m\x00o", "", "mo"},
+		{"national", 0x00, 0x40, "\x03\x25\x01\x01\x1c", "", "Ş"},
+		{"payload", 0x03, 0, "", "mo\xe7o", "moço"},
+	}
+	for _, tc := range test {
+		p := NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.DataCoding, tc.coding)
+		f.Set(pdufield.ESMClass, tc.esm)
+		f.Set(pdufield.ShortMessage, []byte(tc.sm))
+		if tc.payload != "" {
+			p.TLVFields().Set(pdutlv.TagMessagePayload, []byte(tc.payload))
+		}
+		var b bytes.Buffer
+		if err := p.SerializeTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		p, _, _, err := Decode(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := Text(p)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if text != tc.want {
+			t.Fatalf("%s: unexpected text: want %q, have %q", tc.name, tc.want, text)
+		}
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	p := NewSubmitSM(nil)
+	f := p.Fields()
+	f.Set(pdufield.DataCoding, 0x30)
+	f.Set(pdufield.ShortMessage, []byte("compressed"))
+	if _, err := Text(p); err != pdutext.ErrCompressed {
+		t.Fatalf("want ErrCompressed, have %v", err)
+	}
+	f.Set(pdufield.DataCoding, 0x00)
+	f.Set(pdufield.ESMClass, 0x40)
+	f.Set(pdufield.ShortMessage, []byte("\x05\x00\x03"))
+	if _, err := Text(p); err == nil {
+		t.Fatal("want error for invalid user data header, have nil")
+	}
+	if text, err := Text(NewEnquireLink()); text != "" || err != nil {
+		t.Fatalf("unexpected text for enquire_link: %q, %v", text, err)
+	}
+}
@@ -125,6 +125,27 @@ func NewBindTransmitterResp() Body {
 	return b
 }
 
+// Outbind PDU.
+type Outbind struct{ *Codec }
+
+func newOutbind(hdr *Header, raw []byte) *Codec {
+	return &Codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.SystemID,
+			pdufield.Password,
+		},
+		r: raw,
+	}
+}
+
+// NewOutbind creates and initializes a new Outbind PDU.
+func NewOutbind() Body {
+	b := newOutbind(&Header{ID: OutbindID}, nil)
+	b.Init()
+	return b
+}
+
 // QuerySM PDU.
 type QuerySM struct{ *Codec }
 
@@ -171,6 +192,90 @@ func NewQuerySMResp() Body {
 	return b
 }
 
+// CancelSM PDU.
+type CancelSM struct{ *Codec }
+
+func newCancelSM(hdr *Header, raw []byte) *Codec {
+	return &Codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.ServiceType,
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.DestAddrTON,
+			pdufield.DestAddrNPI,
+			pdufield.DestinationAddr,
+		},
+		r: raw,
+	}
+}
+
+// NewCancelSM creates and initializes a new CancelSM PDU.
+func NewCancelSM() Body {
+	b := newCancelSM(&Header{ID: CancelSMID}, nil)
+	b.Init()
+	return b
+}
+
+// CancelSMResp PDU.
+type CancelSMResp struct{ *Codec }
+
+func newCancelSMResp(hdr *Header, raw []byte) *Codec {
+	return &Codec{h: hdr, r: raw}
+}
+
+// NewCancelSMResp creates and initializes a new CancelSMResp PDU.
+func NewCancelSMResp() Body {
+	b := newCancelSMResp(&Header{ID: CancelSMRespID}, nil)
+	b.Init()
+	return b
+}
+
+// ReplaceSM PDU.
+type ReplaceSM struct{ *Codec }
+
+func newReplaceSM(hdr *Header, raw []byte) *Codec {
+	return &Codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.MessageID,
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.ScheduleDeliveryTime,
+			pdufield.ValidityPeriod,
+			pdufield.RegisteredDelivery,
+			pdufield.SMDefaultMsgID,
+			pdufield.SMLength,
+			pdufield.ShortMessage,
+		},
+		r: raw,
+	}
+}
+
+// NewReplaceSM creates and initializes a new ReplaceSM PDU.
+func NewReplaceSM() Body {
+	b := newReplaceSM(&Header{ID: ReplaceSMID}, nil)
+	b.Init()
+	return b
+}
+
+// ReplaceSMResp PDU.
+type ReplaceSMResp struct{ *Codec }
+
+func newReplaceSMResp(hdr *Header, raw []byte) *Codec {
+	return &Codec{h: hdr, r: raw}
+}
+
+// NewReplaceSMResp creates and initializes a new ReplaceSMResp PDU.
+func NewReplaceSMResp() Body {
+	b := newReplaceSMResp(&Header{ID: ReplaceSMRespID}, nil)
+	b.Init()
+	return b
+}
+
 // SubmitSM PDU.
 type SubmitSM struct{ *Codec }
 
@@ -356,6 +461,31 @@ func NewDeliverSMRespSeq(seq uint32) Body {
 	return b
 }
 
+// AlertNotification PDU.
+type AlertNotification struct{ *Codec }
+
+func newAlertNotification(hdr *Header, raw []byte) *Codec {
+	return &Codec{
+		h: hdr,
+		l: pdufield.List{
+			pdufield.SourceAddrTON,
+			pdufield.SourceAddrNPI,
+			pdufield.SourceAddr,
+			pdufield.ESMEAddrTON,
+			pdufield.ESMEAddrNPI,
+			pdufield.ESMEAddr,
+		},
+		r: raw,
+	}
+}
+
+// NewAlertNotification creates and initializes a new AlertNotification PDU.
+func NewAlertNotification() Body {
+	b := newAlertNotification(&Header{ID: AlertNotificationID}, nil)
+	b.Init()
+	return b
+}
+
 // DataSM PDU.
 type DataSM struct{ *Codec }
 
@@ -378,6 +508,16 @@ func newDataSM(hdr *Header, raw []byte) *Codec {
 	)
 }
 
+// NewDataSM creates and initializes a new DataSM PDU.
+func NewDataSM(fields pdutlv.Fields) Body {
+	b := newDataSM(&Header{ID: DataSMID}, nil)
+	b.Init()
+	for tag, value := range fields {
+		b.t.Set(tag, value)
+	}
+	return b
+}
+
 // DataSMResp PDU.
 type DataSMResp struct{ *Codec }
 
@@ -391,10 +531,15 @@ func newDataSMResp(hdr *Header, raw []byte) *Codec {
 	)
 }
 
+// NewDataSMResp creates and initializes a new DataSMResp PDU for a
+// specific seq. The receipted_message_id TLV is only set if messageId
+// is not empty.
 func NewDataSMResp(seq uint32, messageId string) Body {
 	b := newDataSMResp(&Header{ID: DataSMRespID, Seq: seq}, nil)
 	b.Init()
-	b.TLVFields().Set(pdutlv.TagReceiptedMessageID, messageId)
+	if messageId != "" {
+		b.TLVFields().Set(pdutlv.TagReceiptedMessageID, messageId)
+	}
 	return b
 }
 
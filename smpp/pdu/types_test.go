@@ -82,3 +82,81 @@ func TestBindResp(t *testing.T) {
 	t.Log(tx)
 }
 */
+
+func TestCancelSM(t *testing.T) {
+	p := NewCancelSM()
+	f := p.Fields()
+	f.Set(pdufield.MessageID, "13")
+	f.Set(pdufield.SourceAddr, "root")
+	f.Set(pdufield.DestinationAddr, "foobar")
+	var b bytes.Buffer
+	if err := p.SerializeTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	d, _, _, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Header().ID != CancelSMID {
+		t.Fatalf("unexpected ID: want %s, have %s", CancelSMID, d.Header().ID)
+	}
+	test := []struct {
+		n pdufield.Name
+		v string
+	}{
+		{pdufield.MessageID, "13"},
+		{pdufield.SourceAddr, "root"},
+		{pdufield.DestinationAddr, "foobar"},
+	}
+	for _, el := range test {
+		f := d.Fields()[el.n]
+		if f == nil {
+			t.Fatalf("missing field: %s", el.n)
+		}
+		if f.String() != el.v {
+			t.Fatalf("unexpected value for %q: want %q, have %q",
+				el.n, el.v, f.String())
+		}
+	}
+}
+
+func TestReplaceSM(t *testing.T) {
+	p := NewReplaceSM()
+	f := p.Fields()
+	f.Set(pdufield.MessageID, "13")
+	f.Set(pdufield.SourceAddr, "root")
+	f.Set(pdufield.ShortMessage, []byte("hello"))
+	var b bytes.Buffer
+	if err := p.SerializeTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if l := uint32(b.Len()); l != p.Header().Len {
+		t.Fatalf("unexpected len: want %d, have %d", l, p.Header().Len)
+	}
+	d, _, _, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Header().ID != ReplaceSMID {
+		t.Fatalf("unexpected ID: want %s, have %s", ReplaceSMID, d.Header().ID)
+	}
+	test := []struct {
+		n pdufield.Name
+		v string
+	}{
+		{pdufield.MessageID, "13"},
+		{pdufield.SourceAddr, "root"},
+		{pdufield.SMLength, "5"},
+		{pdufield.ShortMessage, "hello"},
+	}
+	for _, el := range test {
+		f := d.Fields()[el.n]
+		if f == nil {
+			t.Fatalf("missing field: %s", el.n)
+		}
+		if f.String() != el.v {
+			t.Fatalf("unexpected value for %q: want %q, have %q",
+				el.n, el.v, f.String())
+		}
+	}
+}
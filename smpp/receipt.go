@@ -0,0 +1,215 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+)
+
+// ErrNotDeliveryReceipt is returned by ParseDeliveryReceipt when the
+// given PDU is not a delivery receipt.
+var ErrNotDeliveryReceipt = errors.New("not a delivery receipt")
+
+// Message type bits of the esm_class field of DeliverSM and DataSM.
+const (
+	esmClassTypeMask             = 0x3c
+	esmClassDeliveryReceipt      = 0x04
+	esmClassIntermediateDelivery = 0x20
+)
+
+// DeliveryReceipt contains the parsed delivery receipt sent by the
+// SMSC in a DeliverSM or DataSM PDU.
+//
+// Src and Dst are the addresses of the receipt PDU, that is, the
+// destination and source of the original message respectively.
+type DeliveryReceipt struct {
+	ID           string              // Message ID from submit_sm_resp.
+	Src          string              // Source address of the receipt.
+	Dst          string              // Destination address of the receipt.
+	Sub          int                 // Number of messages originally submitted.
+	Dlvrd        int                 // Number of messages delivered.
+	SubmitDate   time.Time           // Time the message was submitted.
+	DoneDate     time.Time           // Time the message reached its final state.
+	Stat         string              // Final state, e.g. DELIVRD.
+	State        pdutlv.MessageState // Final state as in the message_state TLV.
+	Err          string              // Network or SMSC specific error code.
+	Text         string              // First characters of the original message.
+	Intermediate bool                // Intermediate delivery notification.
+}
+
+// Delivered returns true if the message state is DELIVERED.
+func (dr *DeliveryReceipt) Delivered() bool {
+	return dr.State == 2
+}
+
+// statState maps the stat values of SMPP 3.4 Appendix B, and some
+// common vendor variations, to the message_state TLV values.
+var statState = map[string]pdutlv.MessageState{
+	"ENROUTE":       1,
+	"DELIVRD":       2,
+	"DELIVERED":     2,
+	"EXPIRED":       3,
+	"DELETED":       4,
+	"UNDELIV":       5,
+	"UNDELIVERABLE": 5,
+	"ACCEPTD":       6,
+	"ACCEPTED":      6,
+	"UNKNOWN":       7,
+	"REJECTD":       8,
+	"REJECTED":      8,
+}
+
+// IsDeliveryReceipt returns true if p is a DeliverSM or DataSM with
+// the esm_class flagged as a delivery receipt or intermediate delivery
+// notification, or with the receipted_message_id TLV set.
+func IsDeliveryReceipt(p pdu.Body) bool {
+	switch p.Header().ID {
+	case pdu.DeliverSMID, pdu.DataSMID:
+	default:
+		return false
+	}
+	if f := p.Fields()[pdufield.ESMClass]; f != nil {
+		if b := f.Bytes(); len(b) > 0 {
+			switch b[0] & esmClassTypeMask {
+			case esmClassDeliveryReceipt, esmClassIntermediateDelivery:
+				return true
+			}
+		}
+	}
+	return p.TLVFields()[pdutlv.TagReceiptedMessageID] != nil
+}
+
+// ParseDeliveryReceipt parses the delivery receipt in p. It returns
+// ErrNotDeliveryReceipt if p is not a delivery receipt.
+//
+// The receipt text is parsed as in SMPP 3.4 Appendix B. The
+// receipted_message_id and message_state TLVs, if present, take
+// precedence over the id and stat values of the text.
+func ParseDeliveryReceipt(p pdu.Body) (*DeliveryReceipt, error) {
+	if !IsDeliveryReceipt(p) {
+		return nil, ErrNotDeliveryReceipt
+	}
+	f := p.Fields()
+	var text string
+	if sm := f[pdufield.ShortMessage]; sm != nil && len(sm.Bytes()) > 0 {
+		text = sm.String()
+	} else if mp := p.TLVFields()[pdutlv.TagMessagePayload]; mp != nil {
+		text = mp.String()
+	}
+	dr := ParseDeliveryReceiptText(text)
+	if v := f[pdufield.SourceAddr]; v != nil {
+		dr.Src = v.String()
+	}
+	if v := f[pdufield.DestinationAddr]; v != nil {
+		dr.Dst = v.String()
+	}
+	if v := f[pdufield.ESMClass]; v != nil && len(v.Bytes()) > 0 {
+		dr.Intermediate = v.Bytes()[0]&esmClassTypeMask == esmClassIntermediateDelivery
+	}
+	tlv := p.TLVFields()
+	if v := tlv[pdutlv.TagReceiptedMessageID]; v != nil {
+		dr.ID = strings.TrimRight(v.String(), "\x00")
+	}
+	if v := tlv[pdutlv.TagMessageStateOption]; v != nil && len(v.Bytes()) > 0 {
+		dr.State = pdutlv.MessageState(v.Bytes()[0])
+	}
+	if dr.ID == "" {
+		return nil, errors.New("delivery receipt without message id")
+	}
+	return dr, nil
+}
+
+// receiptKey matches the keys of the delivery receipt text.
+var receiptKey = regexp.MustCompile(`(?i)(?:^|\s)(id|sub|dlvrd|submit[ _]date|done[ _]date|stat|err|text)\s*:`)
+
+// ParseDeliveryReceiptText parses the delivery receipt text as in
+// SMPP 3.4 Appendix B:
+//
+//	id:IIIIIIIIII sub:SSS dlvrd:DDD submit date:YYMMDDhhmm done date:YYMMDDhhmm stat:DDDDDDD err:E text:...
+//
+// Keys are case insensitive, may be missing or in a different order,
+// and the dates may have seconds. Everything after text: is the text.
+func ParseDeliveryReceiptText(s string) *DeliveryReceipt {
+	dr := &DeliveryReceipt{State: 7} // UNKNOWN
+	m := receiptKey.FindAllStringSubmatchIndex(s, -1)
+	for i, idx := range m {
+		key := strings.ToLower(strings.Replace(s[idx[2]:idx[3]], "_", " ", 1))
+		end := len(s)
+		if i+1 < len(m) && key != "text" {
+			end = m[i+1][0]
+		}
+		v := strings.TrimSpace(s[idx[1]:end])
+		switch key {
+		case "id":
+			dr.ID = v
+		case "sub":
+			dr.Sub, _ = strconv.Atoi(v)
+		case "dlvrd":
+			dr.Dlvrd, _ = strconv.Atoi(v)
+		case "submit date":
+			dr.SubmitDate = parseReceiptDate(v)
+		case "done date":
+			dr.DoneDate = parseReceiptDate(v)
+		case "stat":
+			dr.Stat = strings.ToUpper(v)
+			if st, ok := statState[dr.Stat]; ok {
+				dr.State = st
+			}
+		case "err":
+			dr.Err = v
+		case "text":
+			dr.Text = s[idx[1]:]
+			return dr
+		}
+	}
+	return dr
+}
+
+// parseReceiptDate parses dates in the YYMMDDhhmm format, optionally
+// followed by seconds. It returns the zero time if s is malformed.
+func parseReceiptDate(s string) time.Time {
+	layout := "0601021504"
+	if len(s) == 12 {
+		layout = "060102150405"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ReceiptHandlerFunc is the handler function that a Receiver or
+// Transceiver calls when a delivery receipt arrives.
+type ReceiptHandlerFunc func(dr *DeliveryReceipt)
+
+// handleReceipt passes the delivery receipt in p to the tracker, and
+// to h if not matched by the tracker. It returns false if p is not a
+// receipt or it was not handled.
+func handleReceipt(p pdu.Body, rt *ReceiptTracker, h ReceiptHandlerFunc) bool {
+	if (rt == nil && h == nil) || !IsDeliveryReceipt(p) {
+		return false
+	}
+	dr, err := ParseDeliveryReceipt(p)
+	if err != nil {
+		return false
+	}
+	if rt != nil && rt.Match(dr) {
+		return true
+	}
+	if h != nil {
+		h(dr)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/smpp/smpptest"
+)
+
+func TestParseDeliveryReceiptText(t *testing.T) {
+	test := []struct {
+		text string
+		want DeliveryReceipt
+	}{
+		{
+			"id:0123456789 sub:001 dlvrd:001 submit date:1510221530 done date:1510221531 stat:DELIVRD err:000 text:Lorem ipsum: dolor",
+			DeliveryReceipt{
+				ID:         "0123456789",
+				Sub:        1,
+				Dlvrd:      1,
+				SubmitDate: time.Date(2015, 10, 22, 15, 30, 0, 0, time.UTC),
+				DoneDate:   time.Date(2015, 10, 22, 15, 31, 0, 0, time.UTC),
+				Stat:       "DELIVRD",
+				State:      2,
+				Err:        "000",
+				Text:       "Lorem ipsum: dolor",
+			},
+		},
+		{
+			"ID:abc Submit_Date:151022153012 Done_Date:151022153112 Stat:undeliv Err:12",
+			DeliveryReceipt{
+				ID:         "abc",
+				SubmitDate: time.Date(2015, 10, 22, 15, 30, 12, 0, time.UTC),
+				DoneDate:   time.Date(2015, 10, 22, 15, 31, 12, 0, time.UTC),
+				Stat:       "UNDELIV",
+				State:      5,
+				Err:        "12",
+			},
+		},
+		{
+			"stat:FOOBAR id:1",
+			DeliveryReceipt{ID: "1", Stat: "FOOBAR", State: 7},
+		},
+	}
+	for _, tc := range test {
+		dr := ParseDeliveryReceiptText(tc.text)
+		if *dr != tc.want {
+			t.Fatalf("unexpected receipt for %q:\nwant %#v\nhave %#v", tc.text, tc.want, *dr)
+		}
+	}
+}
+
+func TestParseDeliveryReceipt(t *testing.T) {
+	p := pdu.NewDeliverSM()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, "foobar")
+	f.Set(pdufield.DestinationAddr, "root")
+	f.Set(pdufield.ShortMessage, "Lorem ipsum")
+	if IsDeliveryReceipt(p) {
+		t.Fatal("unexpected receipt")
+	}
+	if _, err := ParseDeliveryReceipt(p); err != ErrNotDeliveryReceipt {
+		t.Fatalf("unexpected error: want %v, have %v", ErrNotDeliveryReceipt, err)
+	}
+	f.Set(pdufield.ESMClass, 0x04)
+	f.Set(pdufield.ShortMessage, "id:1 sub:001 dlvrd:000 stat:ENROUTE err:000 text:")
+	p.TLVFields().Set(pdutlv.TagReceiptedMessageID, "1A")
+	p.TLVFields().Set(pdutlv.TagMessageStateOption, uint8(3))
+	dr, err := ParseDeliveryReceipt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dr.ID != "1A" || dr.State.String() != "EXPIRED" || dr.Stat != "ENROUTE" {
+		t.Fatalf("unexpected receipt: %#v", dr)
+	}
+	if dr.Src != "foobar" || dr.Dst != "root" || dr.Intermediate {
+		t.Fatalf("unexpected receipt: %#v", dr)
+	}
+}
+
+func TestReceiverReceiptHandler(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan *DeliveryReceipt)
+	pc := make(chan pdu.Body)
+	r := &Receiver{
+		Addr:           s.Addr(),
+		User:           smpptest.DefaultUser,
+		Passwd:         smpptest.DefaultPasswd,
+		Handler:        func(p pdu.Body) { pc <- p },
+		ReceiptHandler: func(dr *DeliveryReceipt) { rc <- dr },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	p := pdu.NewDeliverSM()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, "foobar")
+	f.Set(pdufield.DestinationAddr, "root")
+	f.Set(pdufield.ESMClass, 0x04)
+	f.Set(pdufield.ShortMessage, "id:13 sub:001 dlvrd:001 submit date:1510221530 done date:1510221531 stat:DELIVRD err:000 text:Lorem")
+	s.BroadcastMessage(p)
+	select {
+	case dr := <-rc:
+		if dr.ID != "13" || !dr.Delivered() {
+			t.Fatalf("unexpected receipt: %#v", dr)
+		}
+	case <-pc:
+		t.Fatal("receipt sent to Handler")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for receipt")
+	}
+	p = pdu.NewDeliverSM()
+	p.Fields().Set(pdufield.ShortMessage, "Lorem ipsum")
+	s.BroadcastMessage(p)
+	select {
+	case <-pc:
+	case <-rc:
+		t.Fatal("message sent to ReceiptHandler")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+}
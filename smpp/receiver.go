@@ -8,11 +8,13 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/fiorix/go-smpp/smpp/pdu"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
 )
 
 // Receiver implements an SMPP client receiver.
@@ -22,20 +24,34 @@ type Receiver struct {
 	Passwd               string
 	SystemType           string
 	EnquireLink          time.Duration
-	EnquireLinkTimeout   time.Duration // Time after last EnquireLink response when connection considered down
-	BindInterval         time.Duration // Binding retry interval
-	MergeInterval        time.Duration // Time in which Receiver waits for the parts of the long messages
-	MergeCleanupInterval time.Duration // How often to cleanup expired message parts
+	EnquireLinkTimeout   time.Duration         // Time after last EnquireLink response when connection considered down
+	BindInterval         time.Duration         // Binding retry interval
+	MergeInterval        time.Duration         // Time in which Receiver waits for the parts of the long messages
+	MergeCleanupInterval time.Duration         // How often to cleanup expired message parts
+	Store                Store                 // Storage of the message parts being merged, default in memory.
+	MergedHandler        MergedHandlerFunc     // Called for merged long messages instead of Handler, optional.
+	IncompleteHandler    IncompleteHandlerFunc // Called with the parts of long messages that expired incomplete, optional.
 	TLS                  *tls.Config
 	Handler              HandlerFunc
+	AlertHandler         AlertHandlerFunc   // Called for AlertNotification instead of Handler, optional.
+	ReceiptHandler       ReceiptHandlerFunc // Called for delivery receipts instead of Handler, optional.
+	Tracker              *ReceiptTracker    // Matches delivery receipts with submitted messages, optional.
 	SkipAutoRespondIDs   []pdu.ID
 
+	// Credentials expected in the Outbind PDU when the Receiver
+	// is started with Listen.
+	OutbindSystemID string
+	OutbindPasswd   string
+	OutbindTLS      *tls.Config   // TLS server settings of the Outbind listener, optional.
+	OutbindTimeout  time.Duration // Time to wait for the Outbind after accepting a connection, default 10s.
+
 	chanClose chan struct{}
+	ob        *outbindListener
 
-	// struct which holds the map of MergeHolders for the merging of the long incoming messages.
+	// struct which holds the store of message parts for the merging of the long incoming messages.
 	// It is used only if the incoming PDU holds UDH data and Receiver has MergeInterval > 0.
 	mg struct {
-		mergeHolders map[int]*MergeHolder
+		store Store
 		sync.Mutex
 	}
 
@@ -49,7 +65,60 @@ type Receiver struct {
 // when a new PDU arrives.
 type HandlerFunc func(p pdu.Body)
 
+// IncompleteHandlerFunc is the handler function that a Receiver calls
+// with the parts received of a long message, in order, when the other
+// parts don't arrive within its MergeInterval.
+type IncompleteHandlerFunc func(parts []pdu.Body)
+
+// Alert contains the parsed AlertNotification sent by the SMSC when a
+// mobile station becomes available.
+type Alert struct {
+	Src                string
+	SourceAddrTON      uint8
+	SourceAddrNPI      uint8
+	ESMEAddr           string
+	ESMEAddrTON        uint8
+	ESMEAddrNPI        uint8
+	AvailabilityStatus pdutlv.MsAvailabilityStatus
+}
+
+// AlertHandlerFunc is the handler function that a Receiver or
+// Transceiver calls when an AlertNotification arrives.
+type AlertHandlerFunc func(a *Alert)
+
+// newAlert returns a new Alert constructed from an AlertNotification PDU.
+// The availability status defaults to available when the optional
+// ms_availability_status TLV is not present.
+func newAlert(p pdu.Body) *Alert {
+	a := &Alert{}
+	f := p.Fields()
+	if v := f[pdufield.SourceAddr]; v != nil {
+		a.Src = v.String()
+	}
+	if v := f[pdufield.SourceAddrTON]; v != nil {
+		a.SourceAddrTON = v.Bytes()[0]
+	}
+	if v := f[pdufield.SourceAddrNPI]; v != nil {
+		a.SourceAddrNPI = v.Bytes()[0]
+	}
+	if v := f[pdufield.ESMEAddr]; v != nil {
+		a.ESMEAddr = v.String()
+	}
+	if v := f[pdufield.ESMEAddrTON]; v != nil {
+		a.ESMEAddrTON = v.Bytes()[0]
+	}
+	if v := f[pdufield.ESMEAddrNPI]; v != nil {
+		a.ESMEAddrNPI = v.Bytes()[0]
+	}
+	if v := p.TLVFields()[pdutlv.TagMsAvailabilityStatus]; v != nil && len(v.Bytes()) > 0 {
+		a.AvailabilityStatus = pdutlv.MsAvailabilityStatus(v.Bytes()[0])
+	}
+	return a
+}
+
 // MergeHolder is a struct which holds the slice of MessageParts for the merging of a long incoming message.
+//
+// Deprecated: the Receiver keeps the message parts in its Store.
 type MergeHolder struct {
 	MessageID     int
 	MessageParts  []*MessagePart // Slice with the parts of the message
@@ -58,6 +127,8 @@ type MergeHolder struct {
 }
 
 // MessagePart is a struct which holds the data of the part of a long incoming message.
+//
+// Deprecated: the Receiver keeps the message parts in its Store.
 type MessagePart struct {
 	PartID int
 	Data   *bytes.Buffer
@@ -71,7 +142,38 @@ type MessagePart struct {
 func (r *Receiver) Bind() <-chan ConnStatus {
 	r.cl.Lock()
 	defer r.cl.Unlock()
+	return r.bind(nil)
+}
+
+// Listen starts the Receiver for SMSC initiated sessions. Instead of
+// dialing to Addr, it waits for the SMSC to connect on the given
+// listener and send an Outbind PDU, which is validated against
+// OutbindSystemID and OutbindPasswd. The Receiver then binds as
+// receiver on that same connection, updates its status via the
+// returned channel, and calls the registered Handler when new PDU
+// arrives.
+//
+// The listener acts as a server, so connections are only secured
+// with TLS when OutbindTLS is set, with the server certificates.
+// The TLS field holds client settings and is not used by Listen.
+//
+// Connections that don't send a valid Outbind within OutbindTimeout
+// are closed, and the next connection is accepted right away.
+//
+// When the session terminates, the Receiver waits for the next
+// Outbind. The listener is closed when Close is called.
+func (r *Receiver) Listen(l net.Listener) <-chan ConnStatus {
+	r.cl.Lock()
+	defer r.cl.Unlock()
+	if r.cl.client != nil {
+		return r.cl.Status
+	}
+	r.ob = newOutbindListener(l, r.OutbindTLS, r.OutbindSystemID, r.OutbindPasswd, r.OutbindTimeout)
+	return r.bind(r.ob.accept)
+}
 
+// bind must be called with r.cl locked.
+func (r *Receiver) bind(dial func() (Conn, error)) <-chan ConnStatus {
 	r.chanClose = make(chan struct{})
 
 	if r.cl.client != nil {
@@ -84,6 +186,7 @@ func (r *Receiver) Bind() <-chan ConnStatus {
 		EnquireLink:        r.EnquireLink,
 		EnquireLinkTimeout: r.EnquireLinkTimeout,
 		Status:             make(chan ConnStatus, 1),
+		DialFunc:           dial,
 		BindFunc:           r.bindFunc,
 		BindInterval:       r.BindInterval,
 	}
@@ -98,7 +201,12 @@ func (r *Receiver) Bind() <-chan ConnStatus {
 			r.MergeCleanupInterval = 1 * time.Second
 		}
 
-		r.mg.mergeHolders = make(map[int]*MergeHolder)
+		r.mg.Lock()
+		r.mg.store = r.Store
+		if r.mg.store == nil {
+			r.mg.store = NewMemoryStore()
+		}
+		r.mg.Unlock()
 		go r.mergeCleaner()
 	}
 
@@ -120,15 +228,16 @@ func (r *Receiver) bindFunc(c Conn) error {
 			resp.Header().ID)
 	}
 
-	// Clean the map in case of rebind, because message id numbering resets after reconnection
-	// and older IDs are no longer valid
-	if r.MergeInterval > 0 {
+	// Clean the in memory store in case of rebind, because message id numbering resets after
+	// reconnection and older IDs are no longer valid. Parts in a user provided Store are kept,
+	// so that they survive restarts, until they expire.
+	if r.MergeInterval > 0 && r.Store == nil {
 		r.mg.Lock()
-		r.mg.mergeHolders = make(map[int]*MergeHolder)
+		r.mg.store = NewMemoryStore()
 		r.mg.Unlock()
 	}
 
-	if r.Handler != nil {
+	if r.Handler != nil || r.MergedHandler != nil || r.AlertHandler != nil || r.ReceiptHandler != nil || r.Tracker != nil {
 		go r.handlePDU()
 	}
 
@@ -145,122 +254,46 @@ func idInList(id pdu.ID, list []pdu.ID) bool {
 }
 
 func (r *Receiver) handlePDU() {
-	var (
-		ok                bool
-		sm                *pdufield.SM
-		udhList           *pdufield.UDHList
-		msgID, partsCount int
-		mh                *MergeHolder
-		orderedBodies     []*bytes.Buffer
-	)
 	autoRespondDeliver := !idInList(pdu.DeliverSMID, r.SkipAutoRespondIDs)
+	handler := r.Handler
+	if handler == nil {
+		handler = func(p pdu.Body) {}
+	}
 
-loop:
 	for {
 		p, err := r.cl.Read()
 		if err != nil || p == nil {
 			break
 		}
 
-		if p == nil {
-			break
-		}
-
 		if p.Header().ID == pdu.DeliverSMID && autoRespondDeliver { // Send DeliverSMResp
 			pResp := pdu.NewDeliverSMRespSeq(p.Header().Seq)
 			r.cl.Write(pResp)
 		}
 
-		if r.MergeInterval == 0 { // Handle the PDU if merging is not needed
-			r.Handler(p)
+		if p.Header().ID == pdu.AlertNotificationID && r.AlertHandler != nil {
+			r.AlertHandler(newAlert(p))
 			continue
 		}
 
-		sm, ok = p.Fields()[pdufield.ShortMessage].(*pdufield.SM)
-		if !ok {
-			// PDU is malformed, do not process
+		if handleReceipt(p, r.Tracker, r.ReceiptHandler) {
 			continue
 		}
 
-		udhList, ok = p.Fields()[pdufield.GSMUserData].(*pdufield.UDHList)
-		if !ok { // Check if GSMUserData is present inside the PDU, do not try to merge if it's not
-			r.Handler(p)
+		if r.MergeInterval == 0 { // Handle the PDU if merging is not needed
+			handler(p)
 			continue
 		}
 
-		for _, udh := range udhList.Data {
-			switch udh.IEI.Data {
-			case 0x00: // Concatenated short messages, 8-bit reference number
-				if int(udh.IELength.Data) != 3 { // Contains message ID, parts count and part number
-					// PDU is malformed, do not process
-					break
-				}
-
-				// Get message ID and total count of its parts
-				msgID = int(udh.IEData.Data[0])
-				partsCount = int(udh.IEData.Data[1])
-
-				// Check if message part was already added to a MergeHolder
-				r.mg.Lock()
-				if mh, ok = r.mg.mergeHolders[msgID]; !ok {
-					mh = &MergeHolder{
-						MessageID:  msgID,
-						PartsCount: partsCount,
-					}
-
-					r.mg.mergeHolders[msgID] = mh
-				}
-				r.mg.Unlock()
-
-				// Add current part of the message to the slice
-				mh.MessageParts = append(mh.MessageParts, &MessagePart{
-					PartID: int(udh.IEData.Data[2]),
-					Data:   bytes.NewBuffer(sm.Data),
-				})
-				mh.LastWriteTime = time.Now()
-
-				// Check if we have all the parts of the message
-				if len(mh.MessageParts) != mh.PartsCount {
-					continue loop
-				}
-
-				// Order up PDUs
-				orderedBodies = make([]*bytes.Buffer, partsCount)
-				for _, mp := range mh.MessageParts {
-					orderedBodies[mp.PartID-1] = mp.Data
-				}
-
-				// Merge PDUs
-				var buf bytes.Buffer
-				for _, body := range orderedBodies {
-					buf.Write(body.Bytes())
-				}
-
-				p.Fields().Set(pdufield.ShortMessage, buf.Bytes())
-
-				// Handle
-				r.Handler(p)
-			}
-		}
-	}
-}
-
-func (r *Receiver) mergeCleaner() {
-	timer := time.NewTimer(r.MergeCleanupInterval)
-
-	for {
-		select {
-		case <-timer.C:
-			r.mg.Lock()
-			for _, mHolder := range r.mg.mergeHolders {
-				if time.Since(mHolder.LastWriteTime) > r.MergeInterval { // Message has expired, remove
-					delete(r.mg.mergeHolders, mHolder.MessageID)
-				}
-			}
-			r.mg.Unlock()
-
-		case <-r.chanClose:
-			return
+		m, ok := r.merge(p)
+		switch {
+		case !ok: // Not a part of a long message
+			handler(p)
+		case m == nil: // Parts missing
+		case r.MergedHandler != nil:
+			r.MergedHandler(m)
+		default:
+			handler(m.body())
 		}
 	}
 }
@@ -273,5 +306,8 @@ func (r *Receiver) Close() error {
 		return ErrNotConnected
 	}
 	close(r.chanClose)
+	if r.ob != nil {
+		r.ob.Close()
+	}
 	return r.cl.Close()
 }
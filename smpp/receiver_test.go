@@ -5,10 +5,15 @@
 package smpp
 
 import (
+	"bytes"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
 	"github.com/fiorix/go-smpp/smpp/smpptest"
 )
 
@@ -43,4 +48,465 @@ func TestReceiver(t *testing.T) {
 	case <-time.After(time.Second):
 		t.Fatal("timeout waiting for server to echo")
 	}
-}
\ No newline at end of file
+}
+
+func TestReceiverInvalidTLV(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan pdu.Body)
+	r := &Receiver{
+		Addr:    s.Addr(),
+		User:    smpptest.DefaultUser,
+		Passwd:  smpptest.DefaultPasswd,
+		Handler: func(p pdu.Body) { rc <- p },
+	}
+	defer r.Close()
+	status := r.Bind()
+	conn := <-status
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	// sar_msg_ref_num must be 2 octets long.
+	p := pdu.NewDeliverSM()
+	p.Fields().Set(pdufield.ShortMessage, "hello")
+	p.TLVFields().Set(pdutlv.TagSarMsgRefNum, []byte{0x01})
+	s.BroadcastMessage(p)
+	select {
+	case m := <-rc:
+		if v := m.TLVFields()[pdutlv.TagSarMsgRefNum]; v == nil || !bytes.Equal(v.Bytes(), []byte{0x01}) {
+			t.Fatalf("unexpected sar_msg_ref_num: %#v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for deliver_sm")
+	}
+	select {
+	case conn = <-status:
+		t.Fatalf("unexpected status change: %s", conn.Status())
+	case <-time.After(100 * time.Millisecond):
+	}
+	s.BroadcastMessage(pdu.NewGenericNACK())
+	select {
+	case <-rc:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for server to echo")
+	}
+}
+
+func TestReceiverOutbind(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := make(chan pdu.Body)
+	r := &Receiver{
+		User:            smpptest.DefaultUser,
+		Passwd:          smpptest.DefaultPasswd,
+		OutbindSystemID: smpptest.DefaultSystemID,
+		OutbindPasswd:   smpptest.DefaultPasswd,
+		Handler:         func(p pdu.Body) { rc <- p },
+	}
+	defer r.Close()
+	status := r.Listen(l)
+	if err = s.Outbind(l.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	conn := <-status
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	// trigger inbound message from server
+	p := pdu.NewGenericNACK()
+	s.BroadcastMessage(p)
+	// check response.
+	select {
+	case m := <-rc:
+		want, have := *p.Header(), *m.Header()
+		if want != have {
+			t.Fatalf("unexpected PDU: want %#v, have %#v",
+				want, have)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for server to echo")
+	}
+}
+
+func TestReceiverOutbindRetry(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Receiver{
+		User:            smpptest.DefaultUser,
+		Passwd:          smpptest.DefaultPasswd,
+		OutbindSystemID: smpptest.DefaultSystemID,
+		OutbindPasswd:   smpptest.DefaultPasswd,
+		OutbindTimeout:  100 * time.Millisecond,
+	}
+	defer r.Close()
+	status := r.Listen(l)
+	// silent peer, closed after the timeout
+	silent, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	// peer with invalid credentials
+	bad, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Close()
+	p := pdu.NewOutbind()
+	p.Fields().Set(pdufield.SystemID, "foobar")
+	p.Fields().Set(pdufield.Password, "foobar")
+	if err = p.SerializeTo(bad); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Outbind(l.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case conn := <-status:
+		if conn.Status() != Connected {
+			t.Fatal(conn.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for outbind")
+	}
+}
+
+func TestReceiverOutbindClose(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Receiver{}
+	status := r.Listen(l)
+	fd, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	time.Sleep(50 * time.Millisecond) // wait for the connection to be accepted
+	r.Close()
+	// the connection waiting for the Outbind is closed
+	fd.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err = fd.Read(make([]byte, 1)); err == nil {
+		t.Fatal("connection not closed")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("timeout waiting for connection to be closed")
+	}
+	for range status {
+	}
+}
+
+func TestReceiverAlertHandler(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	ac := make(chan *Alert)
+	r := &Receiver{
+		Addr:         s.Addr(),
+		User:         smpptest.DefaultUser,
+		Passwd:       smpptest.DefaultPasswd,
+		AlertHandler: func(a *Alert) { ac <- a },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	p := pdu.NewAlertNotification()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, "5511999999999")
+	f.Set(pdufield.SourceAddrTON, 1)
+	f.Set(pdufield.ESMEAddr, "root")
+	p.TLVFields().Set(pdutlv.TagMsAvailabilityStatus, uint8(2))
+	s.BroadcastMessage(p)
+	select {
+	case a := <-ac:
+		if a.Src != "5511999999999" || a.SourceAddrTON != 1 {
+			t.Fatalf("unexpected source address: %#v", a)
+		}
+		if a.ESMEAddr != "root" {
+			t.Fatalf("unexpected esme address: want root, have %q", a.ESMEAddr)
+		}
+		if a.AvailabilityStatus.String() != "UNAVAILABLE" {
+			t.Fatalf("unexpected status: want UNAVAILABLE, have %s", a.AvailabilityStatus)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for alert")
+	}
+}
+
+// newPart returns a DeliverSM with part seq of total of a long message.
+func newPart(ref, total, seq uint8, text string) pdu.Body {
+	p := pdu.NewDeliverSM()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, "1")
+	f.Set(pdufield.DestinationAddr, "root")
+	f.Set(pdufield.ESMClass, 0x40)
+	f.Set(pdufield.ShortMessage, append([]byte{5, 0x00, 3, ref, total, seq}, text...))
+	return p
+}
+
+func TestReceiverMergeStore(t *testing.T) {
+	store := NewMemoryStore()
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = store
+	if m, ok := r.merge(newPart(42, 2, 2, "ipsum")); m != nil || !ok {
+		t.Fatal("merged incomplete message")
+	}
+	// New receiver with the same store, as after a restart.
+	r = &Receiver{MergeInterval: time.Minute}
+	r.mg.store = store
+	m, _ := r.merge(newPart(42, 2, 1, "Lorem "))
+	if m == nil {
+		t.Fatal("message not merged")
+	}
+	if m.Text != "Lorem ipsum" {
+		t.Fatalf("unexpected message: %q", m.Text)
+	}
+	store.Scan(mergePrefix, func(k string, v []byte) error {
+		t.Fatalf("part not removed from store: %q", k)
+		return nil
+	})
+}
+
+func TestReceiverMergeDuplicates(t *testing.T) {
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = NewMemoryStore()
+	parts := []pdu.Body{
+		newPart(7, 3, 3, "dolor"),
+		newPart(7, 3, 1, "Lorem "),
+		newPart(7, 3, 3, "dolor"),
+		newPart(7, 3, 2, "ipsum "),
+		newPart(7, 3, 1, "Lorem "), // late retransmission
+		newPart(7, 3, 2, "amet, "), // new message reusing the reference
+		newPart(7, 3, 1, "Sit "),
+		newPart(7, 3, 3, "consectetur"),
+	}
+	var merged []*MergedMessage
+	for _, p := range parts {
+		m, ok := r.merge(p)
+		if !ok {
+			t.Fatal("part not merged")
+		}
+		if m != nil {
+			merged = append(merged, m)
+		}
+	}
+	if len(merged) != 2 {
+		t.Fatalf("unexpected number of messages: want 2, have %d", len(merged))
+	}
+	if merged[0].Text != "Lorem ipsum dolor" {
+		t.Fatalf("unexpected message: %q", merged[0].Text)
+	}
+	if merged[1].Text != "Sit amet, consectetur" {
+		t.Fatalf("unexpected message: %q", merged[1].Text)
+	}
+}
+
+func TestReceiverMergeAddrs(t *testing.T) {
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = NewMemoryStore()
+	part := func(src, dst string, seq uint8, text string) pdu.Body {
+		p := newPart(9, 2, seq, text)
+		p.Fields().Set(pdufield.SourceAddr, src)
+		p.Fields().Set(pdufield.DestinationAddr, dst)
+		return p
+	}
+	if m, _ := r.merge(part("a|b", "c", 1, "Lorem ")); m != nil {
+		t.Fatal("merged incomplete message")
+	}
+	if m, _ := r.merge(part("a", "b|c", 2, "ipsum")); m != nil {
+		t.Fatalf("merged parts of different messages: %q", m.Text)
+	}
+	if m, _ := r.merge(part("a/1", "c", 2, "ipsum")); m != nil {
+		t.Fatalf("merged parts of different messages: %q", m.Text)
+	}
+	if m, _ := r.merge(part("a|b", "c", 2, "ipsum")); m == nil || m.Text != "Lorem ipsum" {
+		t.Fatalf("unexpected message: %#v", m)
+	}
+}
+
+func TestReceiverMergedMessage(t *testing.T) {
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = NewMemoryStore()
+	part := func(seq uint8, text string) pdu.Body {
+		p := pdu.NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.SourceAddr, "1")
+		f.Set(pdufield.DestinationAddr, "root")
+		f.Set(pdufield.ESMClass, 0x40)
+		f.Set(pdufield.DataCoding, uint8(pdutext.UCS2Type))
+		udh := []byte{12, 0x05, 4, 0x0b, 0x84, 0x23, 0xf0, 0x08, 4, 0, 7, 2, seq}
+		f.Set(pdufield.ShortMessage, append(udh, pdutext.UCS2(text).Encode()...))
+		return p
+	}
+	r.merge(part(2, "ção"))
+	m, _ := r.merge(part(1, "Informa"))
+	if m == nil {
+		t.Fatal("message not merged")
+	}
+	if m.Text != "Informação" {
+		t.Fatalf("unexpected text: %q", m.Text)
+	}
+	if m.Src != "1" || m.Dst != "root" || m.DataCoding != pdutext.UCS2Type {
+		t.Fatalf("unexpected message: %#v", m)
+	}
+	if len(m.Parts) != 2 {
+		t.Fatalf("unexpected number of parts: want 2, have %d", len(m.Parts))
+	}
+	for i, p := range m.Parts {
+		ies, _ := userData(p)
+		if c, _ := concatInfo(p, ies); c.seq != i+1 {
+			t.Fatalf("unexpected part %d: %#v", i, c)
+		}
+	}
+	if len(m.UDH) != 1 || m.UDH[0].IEI.Data != 0x05 {
+		t.Fatalf("unexpected user data header: %#v", m.UDH)
+	}
+}
+
+func TestReceiverMergeInvalid(t *testing.T) {
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = NewMemoryStore()
+	for _, p := range []pdu.Body{
+		newPart(7, 2, 0, "zero"),
+		newPart(7, 2, 3, "out of range"),
+		newPart(7, 0, 0, "no parts"),
+	} {
+		if _, ok := r.merge(p); ok {
+			t.Fatalf("invalid part merged: %q", p.Fields()[pdufield.ShortMessage])
+		}
+	}
+}
+
+func TestReceiverMergeIncomplete(t *testing.T) {
+	ic := make(chan []pdu.Body, 1)
+	r := &Receiver{
+		MergeInterval:     10 * time.Millisecond,
+		IncompleteHandler: func(parts []pdu.Body) { ic <- parts },
+	}
+	r.mg.store = NewMemoryStore()
+	r.merge(newPart(7, 3, 3, "dolor"))
+	r.merge(newPart(7, 3, 1, "Lorem "))
+	time.Sleep(20 * time.Millisecond)
+	for _, parts := range r.mergeExpire() {
+		r.IncompleteHandler(parts)
+	}
+	select {
+	case parts := <-ic:
+		if len(parts) != 2 {
+			t.Fatalf("unexpected number of parts: want 2, have %d", len(parts))
+		}
+		_, data := userData(parts[0])
+		if string(data) != "Lorem " {
+			t.Fatalf("unexpected first part: %q", data)
+		}
+	default:
+		t.Fatal("incomplete message not expired")
+	}
+	r.mg.store.Scan("", func(k string, v []byte) error {
+		t.Fatalf("expired key not removed from store: %q", k)
+		return nil
+	})
+}
+
+func TestReceiverMerge(t *testing.T) {
+	s := smpptest.NewServer()
+	defer s.Close()
+	rc := make(chan pdu.Body, 10)
+	r := &Receiver{
+		Addr:          s.Addr(),
+		User:          smpptest.DefaultUser,
+		Passwd:        smpptest.DefaultPasswd,
+		MergeInterval: time.Minute,
+		Handler: func(p pdu.Body) {
+			if p.Header().ID == pdu.DeliverSMID { // skip echoed responses
+				rc <- p
+			}
+		},
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	part := func(src string, udh []byte, tlv pdutlv.Fields, text string) pdu.Body {
+		p := pdu.NewDeliverSM()
+		f := p.Fields()
+		f.Set(pdufield.SourceAddr, src)
+		f.Set(pdufield.DestinationAddr, "root")
+		if udh != nil {
+			f.Set(pdufield.ESMClass, 0x40)
+		}
+		f.Set(pdufield.ShortMessage, append(udh, text...))
+		for k, v := range tlv {
+			p.TLVFields().Set(k, v)
+		}
+		return p
+	}
+	sar := func(ref []byte, total, seq uint8) pdutlv.Fields {
+		return pdutlv.Fields{
+			pdutlv.TagSarMsgRefNum:     ref,
+			pdutlv.TagSarTotalSegments: total,
+			pdutlv.TagSarSegmentSeqnum: seq,
+		}
+	}
+	test := []struct {
+		name  string
+		parts []pdu.Body
+	}{
+		{"8-bit reference", []pdu.Body{
+			part("1", []byte{5, 0x00, 3, 7, 2, 2}, nil, "ipsum"),
+			part("2", []byte{5, 0x00, 3, 7, 2, 1}, nil, "dolor "),
+			part("1", []byte{5, 0x00, 3, 7, 2, 1}, nil, "Lorem "),
+		}},
+		{"16-bit reference", []pdu.Body{
+			part("1", []byte{6, 0x08, 4, 1, 7, 2, 1}, nil, "Lorem "),
+			part("1", []byte{6, 0x08, 4, 1, 7, 2, 2}, nil, "ipsum"),
+		}},
+		{"SAR", []pdu.Body{
+			part("1", nil, sar([]byte{2, 9}, 2, 2), "ipsum"),
+			part("1", nil, sar([]byte{2, 9}, 2, 1), "Lorem "),
+		}},
+	}
+	for _, tc := range test {
+		for _, p := range tc.parts {
+			s.BroadcastMessage(p)
+		}
+		select {
+		case m := <-rc:
+			f := m.Fields()
+			if sm := f[pdufield.ShortMessage].String(); sm != "Lorem ipsum" {
+				t.Fatalf("%s: unexpected message: %q", tc.name, sm)
+			}
+			if src := f[pdufield.SourceAddr].String(); src != "1" {
+				t.Fatalf("%s: unexpected source: %q", tc.name, src)
+			}
+			if esm := f[pdufield.ESMClass].Bytes()[0]; esm&0x40 != 0 {
+				t.Fatalf("%s: UDHI flag set in merged message", tc.name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: timeout waiting for merged message", tc.name)
+		}
+	}
+	select {
+	case m := <-rc:
+		t.Fatalf("unexpected message: %q", m.Fields()[pdufield.ShortMessage])
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package server provides an SMPP server for building an SMSC or gateway.
+//
+// The Server authenticates bind requests with a pluggable Authenticator,
+// keeps the bind state of each Session, answers enquire_link and unbind
+// automatically, and dispatches all other requests to the handlers
+// registered per PDU ID. Sessions bound as receiver or transceiver can
+// be used to push deliver_sm PDUs to the client.
+//
+// Clients that don't bind within the BindTimeout are disconnected, and
+// bound clients that stay idle for the IdleTimeout are probed with
+// enquire_link.
+package server
@@ -0,0 +1,260 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+)
+
+var (
+	// ErrServerClosed is returned by Serve and ListenAndServe after
+	// a call to Close or Shutdown.
+	ErrServerClosed = errors.New("smpp: server closed")
+
+	// ErrSessionClosed is returned on attempts to use a closed Session.
+	ErrSessionClosed = errors.New("smpp: session closed")
+
+	// ErrIncorrectBindState is returned on attempts to send PDUs
+	// not allowed in the current bind state of a Session.
+	ErrIncorrectBindState = errors.New("smpp: incorrect bind state")
+
+	// ErrTimeout is returned when we've reached timeout while waiting
+	// for response.
+	ErrTimeout = errors.New("smpp: timeout waiting for response")
+)
+
+// BindRequest contains the credentials of a bind request.
+type BindRequest struct {
+	ID         pdu.ID // BindTransmitterID, BindReceiverID or BindTransceiverID.
+	SystemID   string
+	Password   string
+	SystemType string
+	RemoteAddr net.Addr
+}
+
+// Authenticator validates bind requests.
+//
+// If Authenticate returns a pdu.Status it is used as the status of the
+// bind response, otherwise the bind fails with ESME_RBINDFAIL.
+type Authenticator interface {
+	Authenticate(req *BindRequest) error
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions
+// as Authenticator.
+type AuthenticatorFunc func(req *BindRequest) error
+
+// Authenticate implements the Authenticator interface.
+func (f AuthenticatorFunc) Authenticate(req *BindRequest) error {
+	return f(req)
+}
+
+// HandlerFunc is the signature of a function registered in the Server
+// to handle request PDUs of a given ID, e.g. SubmitSM.
+//
+// The handler returns the response PDU, which is sent back to the client
+// with the same sequence number of the request. If the handler returns
+// an error, a response with that status is sent instead, when the error
+// is a pdu.Status, or with ESME_RSYSERR otherwise. If both are nil, an
+// empty response with status ESME_ROK is sent.
+type HandlerFunc func(s *Session, p pdu.Body) (pdu.Body, error)
+
+// Server is an SMPP server.
+type Server struct {
+	Addr        string        // TCP address to listen on, default ":2775".
+	SystemID    string        // system_id sent in bind responses.
+	TLS         *tls.Config   // TLS server settings, optional.
+	Auth        Authenticator // Authenticator, optional. Accepts all binds if not set.
+	RespTimeout time.Duration // Response timeout for PDUs sent by sessions, default 1s.
+	BindTimeout time.Duration // Time for new clients to bind, default 10s.
+	IdleTimeout time.Duration // Time without PDUs from bound clients before sending enquire_link, default 30s.
+	ErrorLog    *log.Logger   // Error logger, optional. Uses the log package if not set.
+
+	wg       sync.WaitGroup // sessions and running handlers
+	mu       sync.Mutex
+	l        net.Listener
+	closed   bool
+	handlers map[pdu.ID]HandlerFunc
+	sessions map[*Session]struct{}
+}
+
+// Handle registers the handler for the given PDU ID. Handlers for bind,
+// unbind and enquire_link are ignored since those are handled by the
+// server itself.
+func (srv *Server) Handle(id pdu.ID, h HandlerFunc) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.handlers == nil {
+		srv.handlers = make(map[pdu.ID]HandlerFunc)
+	}
+	srv.handlers[id] = h
+}
+
+func (srv *Server) handler(id pdu.ID) HandlerFunc {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.handlers[id]
+}
+
+// ListenAndServe listens on Addr and calls Serve to handle new clients.
+func (srv *Server) ListenAndServe() error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":2775"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts new clients on the given listener and creates a Session
+// for each of them. It blocks until the listener fails or the server is
+// closed, in which case it returns ErrServerClosed.
+func (srv *Server) Serve(l net.Listener) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		return ErrServerClosed
+	}
+	srv.l = l
+	srv.mu.Unlock()
+	for {
+		fd, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if srv.TLS != nil {
+			fd = tls.Server(fd, srv.TLS)
+		}
+		s := newSession(srv, fd)
+		if !srv.track(s) {
+			fd.Close()
+			return ErrServerClosed
+		}
+		go s.serve()
+	}
+}
+
+// Sessions returns the currently open sessions.
+func (srv *Server) Sessions() []*Session {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	sessions := make([]*Session, 0, len(srv.sessions))
+	for s := range srv.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// Close immediately closes the listener and all sessions.
+func (srv *Server) Close() error {
+	err := srv.stop()
+	for _, s := range srv.Sessions() {
+		s.Close()
+	}
+	return err
+}
+
+// Shutdown gracefully shuts down the server. It closes the listener,
+// sends unbind to all sessions and waits for them to terminate, and
+// for their running handlers to return. If the context expires before
+// that, the remaining sessions are closed and the context error is
+// returned.
+func (srv *Server) Shutdown(ctx context.Context) error {
+	srv.stop()
+	for _, s := range srv.Sessions() {
+		go s.Unbind()
+	}
+	done := make(chan struct{})
+	go func() {
+		srv.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		srv.Close()
+		return ctx.Err()
+	}
+}
+
+// stop marks the server as closed and closes the listener.
+func (srv *Server) stop() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.closed = true
+	if srv.l == nil {
+		return nil
+	}
+	return srv.l.Close()
+}
+
+// track adds s to the list of open sessions, or returns false if the
+// server is closed.
+func (srv *Server) track(s *Session) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed {
+		return false
+	}
+	if srv.sessions == nil {
+		srv.sessions = make(map[*Session]struct{})
+	}
+	srv.sessions[s] = struct{}{}
+	srv.wg.Add(1) // done when s.serve returns
+	return true
+}
+
+func (srv *Server) untrack(s *Session) {
+	srv.mu.Lock()
+	delete(srv.sessions, s)
+	srv.mu.Unlock()
+}
+
+func (srv *Server) respTimeout() time.Duration {
+	if srv.RespTimeout == 0 {
+		return time.Second
+	}
+	return srv.RespTimeout
+}
+
+func (srv *Server) bindTimeout() time.Duration {
+	if srv.BindTimeout == 0 {
+		return 10 * time.Second
+	}
+	return srv.BindTimeout
+}
+
+func (srv *Server) idleTimeout() time.Duration {
+	if srv.IdleTimeout == 0 {
+		return 30 * time.Second
+	}
+	return srv.IdleTimeout
+}
+
+func (srv *Server) logf(format string, v ...interface{}) {
+	if srv.ErrorLog != nil {
+		srv.ErrorLog.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
@@ -0,0 +1,380 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp"
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	srv := testServer()
+	return srv, serveTest(t, srv)
+}
+
+// testServer returns a Server that accepts the client/secret bind.
+func testServer() *Server {
+	return &Server{
+		SystemID: "server",
+		Auth: AuthenticatorFunc(func(req *BindRequest) error {
+			if req.SystemID != "client" {
+				return pdu.Status(0x0f) // invalid system id
+			}
+			if req.Password != "secret" {
+				return pdu.Status(0x0e) // invalid password
+			}
+			return nil
+		}),
+	}
+}
+
+// serveTest starts srv on a local address and returns the address.
+func serveTest(t *testing.T, srv *Server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	return l.Addr().String()
+}
+
+func TestServerSubmit(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	srv.Handle(pdu.SubmitSMID, func(s *Session, p pdu.Body) (pdu.Body, error) {
+		if s.State() != BoundTX {
+			t.Errorf("unexpected state: want %s, have %s", BoundTX, s.State())
+		}
+		resp := pdu.NewSubmitSMResp()
+		resp.Fields().Set(pdufield.MessageID, "foobar")
+		return resp, nil
+	})
+	tx := &smpp.Transmitter{
+		Addr:   addr,
+		User:   "client",
+		Passwd: "secret",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	sm, err := tx.Submit(&smpp.ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := sm.RespID(); id != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", id)
+	}
+	// PDUs without a registered handler are rejected.
+	_, err = tx.QuerySM("root", "13", 0, 0)
+	if err != pdu.Status(0x03) {
+		t.Fatalf("unexpected error: want invalid command id, have %v", err)
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	tx := &smpp.Transmitter{
+		Addr:   addr,
+		User:   "client",
+		Passwd: "wrong",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.BindFailed {
+		t.Fatalf("unexpected status: want %s, have %s", smpp.BindFailed, conn.Status())
+	}
+	if conn.Error() != pdu.Status(0x0e) {
+		t.Fatalf("unexpected error: want invalid password, have %v", conn.Error())
+	}
+}
+
+func TestServerDeliver(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	rc := make(chan pdu.Body, 1)
+	r := &smpp.Receiver{
+		Addr:    addr,
+		User:    "client",
+		Passwd:  "secret",
+		Handler: func(p pdu.Body) { rc <- p },
+	}
+	defer r.Close()
+	conn := <-r.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	sessions := srv.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("unexpected # of sessions: want 1, have %d", len(sessions))
+	}
+	s := sessions[0]
+	if s.State() != BoundRX || s.SystemID() != "client" {
+		t.Fatalf("unexpected session: %s %q", s.State(), s.SystemID())
+	}
+	p := pdu.NewDeliverSM()
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, "foobar")
+	f.Set(pdufield.DestinationAddr, "root")
+	f.Set(pdufield.ShortMessage, pdutext.Raw("Lorem ipsum"))
+	resp, err := s.Deliver(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header().ID != pdu.DeliverSMRespID {
+		t.Fatalf("unexpected response: %s", resp.Header().ID)
+	}
+	select {
+	case m := <-rc:
+		if sm := m.Fields()[pdufield.ShortMessage]; sm.String() != "Lorem ipsum" {
+			t.Fatalf("unexpected short message: %q", sm)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for deliver_sm")
+	}
+}
+
+func TestServerSessionState(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	r := bufio.NewReader(c)
+	// submit_sm before bind
+	p := pdu.NewSubmitSM(nil)
+	if err = p.SerializeTo(c); err != nil {
+		t.Fatal(err)
+	}
+	resp, _, _, err := pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header().ID != pdu.SubmitSMRespID || resp.Header().Status != 0x04 {
+		t.Fatalf("unexpected response: %#v", resp.Header())
+	}
+	// enquire_link
+	p = pdu.NewEnquireLink()
+	if err = p.SerializeTo(c); err != nil {
+		t.Fatal(err)
+	}
+	resp, _, _, err = pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header().ID != pdu.EnquireLinkRespID || resp.Header().Seq != p.Header().Seq {
+		t.Fatalf("unexpected response: %#v", resp.Header())
+	}
+	// unknown PDU
+	c.Write([]byte{
+		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x01, 0xFF,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2A,
+	})
+	resp, _, _, err = pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := resp.Header()
+	if h.ID != pdu.GenericNACKID || h.Status != 0x03 || h.Seq != 0x2A {
+		t.Fatalf("unexpected response: %#v", h)
+	}
+}
+
+func TestServerInvalidCommandLength(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Write([]byte{
+		0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x15,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2A,
+	})
+	r := bufio.NewReader(c)
+	resp, _, _, err := pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := resp.Header()
+	if h.ID != pdu.GenericNACKID || h.Status != 0x02 || h.Seq != 0x2A {
+		t.Fatalf("unexpected response: %#v", h)
+	}
+	if _, _, _, err = pdu.Decode(r); err == nil {
+		t.Fatal("unexpected open session")
+	}
+}
+
+func TestServerNilResponse(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	srv.Handle(pdu.SubmitSMID, func(s *Session, p pdu.Body) (pdu.Body, error) {
+		return nil, nil
+	})
+	tx := &smpp.Transmitter{
+		Addr:   addr,
+		User:   "client",
+		Passwd: "secret",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	sm, err := tx.Submit(&smpp.ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := sm.Resp().Header(); h.ID != pdu.SubmitSMRespID || h.Status != 0 {
+		t.Fatalf("unexpected response: %#v", h)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	srv := testServer()
+	srv.RespTimeout = 100 * time.Millisecond
+	addr := serveTest(t, srv)
+	tx := &smpp.Transmitter{
+		Addr:   addr,
+		User:   "client",
+		Passwd: "secret",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Sessions()); n != 0 {
+		t.Fatalf("unexpected # of sessions: want 0, have %d", n)
+	}
+	if err := srv.Serve(nil); err != ErrServerClosed {
+		t.Fatalf("unexpected error: want %v, have %v", ErrServerClosed, err)
+	}
+}
+
+func TestServerShutdownHandlers(t *testing.T) {
+	srv := testServer()
+	srv.RespTimeout = 100 * time.Millisecond
+	addr := serveTest(t, srv)
+	started := make(chan struct{})
+	var done int32
+	srv.Handle(pdu.SubmitSMID, func(s *Session, p pdu.Body) (pdu.Body, error) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		atomic.StoreInt32(&done, 1)
+		return nil, nil
+	})
+	tx := &smpp.Transmitter{
+		Addr:   addr,
+		User:   "client",
+		Passwd: "secret",
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	if conn.Status() != smpp.Connected {
+		t.Fatal(conn.Error())
+	}
+	go tx.Submit(&smpp.ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&done) != 1 {
+		t.Fatal("shutdown returned before the handler")
+	}
+}
+
+func TestServerBindTimeout(t *testing.T) {
+	srv := testServer()
+	srv.BindTimeout = 100 * time.Millisecond
+	addr := serveTest(t, srv)
+	defer srv.Close()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err = c.Read(make([]byte, 1)); err == nil {
+		t.Fatal("unexpected data from server")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("session not closed")
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	srv := testServer()
+	srv.IdleTimeout = 100 * time.Millisecond
+	srv.RespTimeout = 100 * time.Millisecond
+	addr := serveTest(t, srv)
+	defer srv.Close()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(c)
+	p := pdu.NewBindTransmitter()
+	p.Fields().Set(pdufield.SystemID, "client")
+	p.Fields().Set(pdufield.Password, "secret")
+	if err = p.SerializeTo(c); err != nil {
+		t.Fatal(err)
+	}
+	resp, _, _, err := pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := resp.Header(); h.ID != pdu.BindTransmitterRespID || h.Status != 0 {
+		t.Fatalf("unexpected response: %#v", h)
+	}
+	// idle client is probed with enquire_link
+	resp, _, _, err = pdu.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := resp.Header(); h.ID != pdu.EnquireLinkID {
+		t.Fatalf("unexpected PDU: %#v", h)
+	}
+	// and disconnected when it doesn't answer
+	if _, _, _, err = pdu.Decode(r); err == nil {
+		t.Fatal("unexpected data from server")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("session not closed")
+	}
+}
@@ -0,0 +1,426 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+)
+
+// State is the bind state of a Session.
+type State uint8
+
+// Supported bind states.
+const (
+	Open State = iota
+	BoundTX
+	BoundRX
+	BoundTRX
+	Unbound
+)
+
+var stateText = map[State]string{
+	Open:     "open",
+	BoundTX:  "bound_tx",
+	BoundRX:  "bound_rx",
+	BoundTRX: "bound_trx",
+	Unbound:  "unbound",
+}
+
+// String implements the Stringer interface.
+func (st State) String() string {
+	return stateText[st]
+}
+
+// Command status codes used by the server.
+const (
+	statusInvalidCommandLength pdu.Status = 0x00000002
+	statusInvalidCommandID     pdu.Status = 0x00000003
+	statusIncorrectBindStatus  pdu.Status = 0x00000004
+	statusAlreadyBound         pdu.Status = 0x00000005
+	statusSystemError          pdu.Status = 0x00000008
+	statusBindFailed           pdu.Status = 0x0000000d
+)
+
+// allows returns true if a client in the given state is allowed to
+// send a request with the given PDU ID.
+func (st State) allows(id pdu.ID) bool {
+	switch id {
+	case
+		pdu.SubmitSMID,
+		pdu.SubmitMultiID,
+		pdu.DataSMID,
+		pdu.QuerySMID,
+		pdu.CancelSMID,
+		pdu.ReplaceSMID:
+		return st == BoundTX || st == BoundTRX
+	case pdu.DeliverSMID, pdu.AlertNotificationID, pdu.OutbindID:
+		return false // SMSC to ESME only.
+	}
+	return st == BoundTX || st == BoundRX || st == BoundTRX
+}
+
+// Session is a client connection to the Server.
+type Session struct {
+	srv  *Server
+	rwc  net.Conn
+	r    *bufio.Reader
+	done chan struct{}
+	once sync.Once
+
+	w struct {
+		sync.Mutex
+		*bufio.Writer
+	}
+
+	mu       sync.Mutex
+	state    State
+	systemID string
+	inflight map[uint32]chan pdu.Body
+}
+
+func newSession(srv *Server, fd net.Conn) *Session {
+	s := &Session{
+		srv:      srv,
+		rwc:      fd,
+		r:        bufio.NewReader(fd),
+		done:     make(chan struct{}),
+		inflight: make(map[uint32]chan pdu.Body),
+	}
+	s.w.Writer = bufio.NewWriter(fd)
+	return s
+}
+
+// State returns the current bind state of the session.
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// SystemID returns the system_id the client is bound with, or an empty
+// string if the session is not bound yet.
+func (s *Session) SystemID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.systemID
+}
+
+// RemoteAddr returns the peer address.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.rwc.RemoteAddr()
+}
+
+// Write serializes the given PDU and writes to the connection.
+func (s *Session) Write(p pdu.Body) error {
+	var b bytes.Buffer
+	err := p.SerializeTo(&b)
+	if err != nil {
+		return err
+	}
+	s.w.Lock()
+	defer s.w.Unlock()
+	_, err = b.WriteTo(s.w.Writer)
+	if err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// Deliver sends the given PDU, normally DeliverSM or DataSM, to the
+// client and waits for the response. It returns ErrIncorrectBindState
+// if the client is not bound as receiver or transceiver.
+//
+// If the response has an error status, both the response and the
+// status are returned.
+func (s *Session) Deliver(p pdu.Body) (pdu.Body, error) {
+	if st := s.State(); st != BoundRX && st != BoundTRX {
+		return nil, ErrIncorrectBindState
+	}
+	return s.do(p)
+}
+
+// Unbind sends unbind to the client, waits for the response or the
+// configured response timeout, and closes the session.
+func (s *Session) Unbind() error {
+	defer s.Close()
+	s.setState(Unbound)
+	_, err := s.do(pdu.NewUnbind())
+	return err
+}
+
+// Close terminates the session.
+func (s *Session) Close() error {
+	var err error
+	s.once.Do(func() {
+		s.srv.untrack(s)
+		s.setState(Unbound)
+		err = s.rwc.Close()
+		close(s.done)
+	})
+	return err
+}
+
+// Done returns a channel that is closed when the session terminates.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Session) setState(st State) {
+	s.mu.Lock()
+	s.state = st
+	s.mu.Unlock()
+}
+
+// do writes p and waits for its response.
+func (s *Session) do(p pdu.Body) (pdu.Body, error) {
+	seq := p.Header().Seq
+	rc := make(chan pdu.Body, 1)
+	s.mu.Lock()
+	s.inflight[seq] = rc
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inflight, seq)
+		s.mu.Unlock()
+	}()
+	if err := s.Write(p); err != nil {
+		return nil, err
+	}
+	select {
+	case resp := <-rc:
+		if st := resp.Header().Status; st != 0 {
+			return resp, st
+		}
+		return resp, nil
+	case <-time.After(s.srv.respTimeout()):
+		return nil, ErrTimeout
+	case <-s.done:
+		return nil, ErrSessionClosed
+	}
+}
+
+// serve reads PDUs off the wire until the connection is closed.
+//
+// Clients must bind within the BindTimeout of the server. Bound clients
+// that send nothing for the IdleTimeout are sent an enquire_link, and
+// the session is closed if they don't send anything back within the
+// RespTimeout.
+func (s *Session) serve() {
+	defer s.srv.wg.Done()
+	defer s.Close()
+	bindDeadline := time.Now().Add(s.srv.bindTimeout())
+	probed := false
+	for {
+		switch {
+		case s.State() == Open:
+			s.rwc.SetReadDeadline(bindDeadline)
+		case probed:
+			s.rwc.SetReadDeadline(time.Now().Add(s.srv.respTimeout()))
+		default:
+			s.rwc.SetReadDeadline(time.Now().Add(s.srv.idleTimeout()))
+		}
+		hdr, err := s.r.Peek(pdu.HeaderLen)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() && s.State() != Open && !probed {
+				probed = true
+				s.Write(pdu.NewEnquireLink())
+				continue
+			}
+			return
+		}
+		probed = false
+		// Keep the sequence number of the raw header, since there is
+		// no decoded header when command_length is invalid.
+		seq := binary.BigEndian.Uint32(hdr[12:16])
+		p, h, _, err := pdu.Decode(s.r)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return
+			}
+			if _, ok := err.(net.Error); ok {
+				return
+			}
+			if h == nil {
+				// The stream can't be resynchronized without a
+				// valid command_length, so the session is closed.
+				s.nack(seq, statusInvalidCommandLength)
+				return
+			}
+			// The PDU was read off the wire but could not be decoded.
+			status := statusInvalidCommandLength
+			if h.ID.String() == "" {
+				status = statusInvalidCommandID
+			}
+			s.nack(h.Seq, status)
+			continue
+		}
+		s.dispatch(p)
+	}
+}
+
+// dispatch handles a single PDU received from the client.
+func (s *Session) dispatch(p pdu.Body) {
+	h := p.Header()
+	if h.ID&pdu.GenericNACKID != 0 { // Response
+		s.mu.Lock()
+		rc := s.inflight[h.Seq]
+		s.mu.Unlock()
+		if rc != nil {
+			rc <- p
+		}
+		return
+	}
+	switch h.ID {
+	case pdu.BindTransmitterID, pdu.BindReceiverID, pdu.BindTransceiverID:
+		s.bind(p)
+		return
+	case pdu.EnquireLinkID:
+		s.Write(pdu.NewEnquireLinkRespSeq(h.Seq))
+		return
+	case pdu.UnbindID:
+		s.setState(Unbound)
+		resp := pdu.NewUnbindResp()
+		resp.Header().Seq = h.Seq
+		s.Write(resp)
+		s.Close()
+		return
+	}
+	if !s.State().allows(h.ID) {
+		s.respond(p, nil, statusIncorrectBindStatus)
+		return
+	}
+	handler := s.srv.handler(h.ID)
+	if handler == nil {
+		s.respond(p, nil, statusInvalidCommandID)
+		return
+	}
+	s.srv.wg.Add(1)
+	go func() {
+		defer s.srv.wg.Done()
+		resp, err := handler(s, p)
+		s.respond(p, resp, err)
+	}()
+}
+
+// respond sends the response of request p. If err is set, a new
+// response is created with the error status. If resp is nil, an
+// empty response with status ESME_ROK is sent instead.
+func (s *Session) respond(p, resp pdu.Body, err error) {
+	if err != nil {
+		status, ok := err.(pdu.Status)
+		if !ok {
+			s.srv.logf("smpp: %s handler failed: %v", p.Header().ID, err)
+			status = statusSystemError
+		}
+		if resp = newResp(p.Header().ID); resp == nil {
+			s.nack(p.Header().Seq, status)
+			return
+		}
+		resp.Header().Status = status
+	}
+	if resp == nil {
+		if resp = newResp(p.Header().ID); resp == nil {
+			s.srv.logf("smpp: %s handler returned no response", p.Header().ID)
+			s.nack(p.Header().Seq, statusSystemError)
+			return
+		}
+	}
+	resp.Header().Seq = p.Header().Seq
+	if err := s.Write(resp); err != nil {
+		s.srv.logf("smpp: failed to write %s: %v", resp.Header().ID, err)
+	}
+}
+
+func (s *Session) nack(seq uint32, status pdu.Status) {
+	p := pdu.NewGenericNACK()
+	p.Header().Seq = seq
+	p.Header().Status = status
+	s.Write(p)
+}
+
+// bind authenticates the client and updates the session state.
+func (s *Session) bind(p pdu.Body) {
+	var (
+		resp pdu.Body
+		st   State
+	)
+	switch p.Header().ID {
+	case pdu.BindTransmitterID:
+		resp, st = pdu.NewBindTransmitterResp(), BoundTX
+	case pdu.BindReceiverID:
+		resp, st = pdu.NewBindReceiverResp(), BoundRX
+	case pdu.BindTransceiverID:
+		resp, st = pdu.NewBindTransceiverResp(), BoundTRX
+	}
+	resp.Header().Seq = p.Header().Seq
+	resp.Fields().Set(pdufield.SystemID, s.srv.SystemID)
+	if s.State() != Open {
+		resp.Header().Status = statusAlreadyBound
+		s.Write(resp)
+		return
+	}
+	f := p.Fields()
+	req := &BindRequest{
+		ID:         p.Header().ID,
+		SystemID:   fieldString(f, pdufield.SystemID),
+		Password:   fieldString(f, pdufield.Password),
+		SystemType: fieldString(f, pdufield.SystemType),
+		RemoteAddr: s.RemoteAddr(),
+	}
+	if s.srv.Auth != nil {
+		if err := s.srv.Auth.Authenticate(req); err != nil {
+			status, ok := err.(pdu.Status)
+			if !ok {
+				status = statusBindFailed
+			}
+			resp.Header().Status = status
+			s.Write(resp)
+			return
+		}
+	}
+	s.mu.Lock()
+	s.state = st
+	s.systemID = req.SystemID
+	s.mu.Unlock()
+	s.Write(resp)
+}
+
+func fieldString(f pdufield.Map, n pdufield.Name) string {
+	if v := f[n]; v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// newResp returns a new response PDU for the given request ID, or nil
+// if the request has no specific response.
+func newResp(id pdu.ID) pdu.Body {
+	switch id {
+	case pdu.SubmitSMID:
+		return pdu.NewSubmitSMResp()
+	case pdu.SubmitMultiID:
+		return pdu.NewSubmitMultiResp()
+	case pdu.DataSMID:
+		return pdu.NewDataSMResp(0, "")
+	case pdu.QuerySMID:
+		return pdu.NewQuerySMResp()
+	case pdu.CancelSMID:
+		return pdu.NewCancelSMResp()
+	case pdu.ReplaceSMID:
+		return pdu.NewReplaceSMResp()
+	case pdu.DeliverSMID:
+		return pdu.NewDeliverSMResp()
+	}
+	return nil
+}
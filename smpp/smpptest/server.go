@@ -109,6 +109,28 @@ func (srv *Server) Serve() {
 	}
 }
 
+// Outbind connects to an ESME listening on addr and sends an Outbind
+// PDU with DefaultSystemID and the server password. The connection is
+// then handled as any other client, starting with the bind request.
+func (srv *Server) Outbind(addr string) error {
+	fd, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	c := newConn(fd)
+	p := pdu.NewOutbind()
+	f := p.Fields()
+	f.Set(pdufield.SystemID, DefaultSystemID)
+	f.Set(pdufield.Password, srv.Passwd)
+	if err = c.Write(p); err != nil {
+		c.Close()
+		return err
+	}
+	srv.conns = append(srv.conns, c)
+	go srv.handle(c)
+	return nil
+}
+
 // BroadcastMessage broadcasts a test PDU to the all bound clients
 func (srv *Server) BroadcastMessage(p pdu.Body) {
 	for i := range srv.conns {
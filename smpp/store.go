@@ -0,0 +1,333 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store is a key-value store for state that should survive restarts,
+// such as the parts of long messages being merged by a Receiver and
+// the messages waiting for delivery receipts in a ReceiptTracker.
+//
+// Implementations must be safe for concurrent use. The same Store can
+// be shared by multiple users, which keep their keys under distinct
+// prefixes.
+type Store interface {
+	// Get returns the value of key, or nil if not found.
+	Get(key string) ([]byte, error)
+
+	// Put sets the value of key.
+	Put(key string, value []byte) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+
+	// Scan calls fn for every key with the given prefix, in key order.
+	// The store may be modified from fn. Scan stops at the first error
+	// returned by fn, and returns it.
+	Scan(prefix string, fn func(key string, value []byte) error) error
+}
+
+// MemoryStore is a Store that keeps data in memory.
+type MemoryStore struct {
+	mu sync.RWMutex
+	m  map[string][]byte
+}
+
+// NewMemoryStore returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{m: make(map[string][]byte)}
+}
+
+// Get implements the Store interface.
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m[key], nil
+}
+
+// Put implements the Store interface.
+func (s *MemoryStore) Put(key string, value []byte) error {
+	v := make([]byte, len(value))
+	copy(v, value)
+	s.mu.Lock()
+	s.m[key] = v
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.m, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Scan implements the Store interface.
+func (s *MemoryStore) Scan(prefix string, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.m))
+	values := make(map[string][]byte)
+	for k, v := range s.m {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+			values[k] = v
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrStoreClosed is returned by FileStore operations after Close.
+var ErrStoreClosed = errors.New("store closed")
+
+// Operations of the FileStore journal.
+const (
+	fileStorePut byte = iota + 1
+	fileStoreDelete
+)
+
+// FileStore is a Store that keeps data in memory and persists every
+// change to a journal file, which is replayed when the store is opened.
+//
+// The journal is compacted when opened, and whenever the space used by
+// stale records exceeds the space used by live ones. Writes are not
+// synced to disk unless Sync is called.
+type FileStore struct {
+	mu    sync.Mutex
+	mem   *MemoryStore
+	path  string
+	f     *os.File
+	size  int64 // journal size
+	live  int64 // size of the records of current keys
+	sizes map[string]int64
+}
+
+// OpenFileStore opens or creates the journal file at path, and returns
+// a FileStore with its contents.
+//
+// A partially written record at the end of the journal, e.g. after a
+// crash, is discarded.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		mem:   NewMemoryStore(),
+		path:  path,
+		sizes: make(map[string]int64),
+	}
+	f, err := os.Open(path)
+	if err == nil {
+		err = s.replay(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err = s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay applies the journal records read from r.
+func (s *FileStore) replay(r *bufio.Reader) error {
+	for {
+		op, key, value, err := readFileStoreRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch op {
+		case fileStorePut:
+			s.mem.m[key] = value
+		case fileStoreDelete:
+			delete(s.mem.m, key)
+		default:
+			return errors.New("corrupted store journal")
+		}
+	}
+}
+
+// compact rewrites the journal with the current keys only, and opens
+// it for appending.
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var size int64
+	sizes := make(map[string]int64, len(s.mem.m))
+	for k, v := range s.mem.m {
+		b := fileStoreRecord(fileStorePut, k, v)
+		if _, err = w.Write(b); err != nil {
+			break
+		}
+		size += int64(len(b))
+		sizes[k] = int64(len(b))
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if s.f != nil {
+		s.f.Close()
+	}
+	s.f, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.size, s.live, s.sizes = size, size, sizes
+	return nil
+}
+
+// Get implements the Store interface.
+func (s *FileStore) Get(key string) ([]byte, error) {
+	return s.mem.Get(key)
+}
+
+// Put implements the Store interface.
+func (s *FileStore) Put(key string, value []byte) error {
+	return s.write(fileStorePut, key, value)
+}
+
+// Delete implements the Store interface.
+func (s *FileStore) Delete(key string) error {
+	return s.write(fileStoreDelete, key, nil)
+}
+
+// Scan implements the Store interface.
+func (s *FileStore) Scan(prefix string, fn func(key string, value []byte) error) error {
+	return s.mem.Scan(prefix, fn)
+}
+
+func (s *FileStore) write(op byte, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return ErrStoreClosed
+	}
+	if op == fileStoreDelete {
+		if _, ok := s.sizes[key]; !ok {
+			return nil
+		}
+	}
+	b := fileStoreRecord(op, key, value)
+	if _, err := s.f.Write(b); err != nil {
+		return err
+	}
+	s.size += int64(len(b))
+	s.live -= s.sizes[key]
+	if op == fileStorePut {
+		s.mem.Put(key, value)
+		s.sizes[key] = int64(len(b))
+		s.live += int64(len(b))
+	} else {
+		s.mem.Delete(key)
+		delete(s.sizes, key)
+	}
+	if stale := s.size - s.live; stale > s.live && stale > 1<<20 {
+		return s.compact()
+	}
+	return nil
+}
+
+// Sync commits the journal to disk.
+func (s *FileStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return ErrStoreClosed
+	}
+	return s.f.Sync()
+}
+
+// Close syncs and closes the journal.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return ErrStoreClosed
+	}
+	err := s.f.Sync()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	s.f = nil
+	return err
+}
+
+// fileStoreRecord encodes a journal record as the operation byte
+// followed by the length prefixed key and value.
+func fileStoreRecord(op byte, key string, value []byte) []byte {
+	var b bytes.Buffer
+	var n [binary.MaxVarintLen64]byte
+	b.WriteByte(op)
+	b.Write(n[:binary.PutUvarint(n[:], uint64(len(key)))])
+	b.WriteString(key)
+	b.Write(n[:binary.PutUvarint(n[:], uint64(len(value)))])
+	b.Write(value)
+	return b.Bytes()
+}
+
+func readFileStoreRecord(r *bufio.Reader) (op byte, key string, value []byte, err error) {
+	if op, err = r.ReadByte(); err != nil {
+		return
+	}
+	k, err := readFileStoreBytes(r)
+	if err != nil {
+		return
+	}
+	value, err = readFileStoreBytes(r)
+	return op, string(k), value, err
+}
+
+func readFileStoreBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n > 1<<30 {
+		return nil, errors.New("corrupted store journal")
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testStore(t *testing.T, s Store) {
+	if err := s.Put("a/2", []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	s.Put("a/1", []byte("one"))
+	s.Put("b/1", []byte("other"))
+	s.Put("a/3", []byte("three"))
+	if err := s.Delete("a/3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("a/4"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := s.Get("a/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v) != "two" {
+		t.Fatalf("unexpected value: want two, have %q", v)
+	}
+	if v, _ = s.Get("a/3"); v != nil {
+		t.Fatalf("unexpected value for deleted key: %q", v)
+	}
+	var have []string
+	err = s.Scan("a/", func(k string, v []byte) error {
+		have = append(have, k+"="+string(v))
+		return s.Delete(k)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a/1=one a/2=two"; strings.Join(have, " ") != want {
+		t.Fatalf("unexpected scan: want %q, have %q", want, have)
+	}
+	if v, _ = s.Get("a/1"); v != nil {
+		t.Fatalf("unexpected value for deleted key: %q", v)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smpp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+	s.Put("c", []byte("foobar"))
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Put("c", nil); err != ErrStoreClosed {
+		t.Fatalf("unexpected error: want %v, have %v", ErrStoreClosed, err)
+	}
+	// Append a partially written record, which must be discarded.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(fileStoreRecord(fileStorePut, "d", []byte("lorem ipsum"))[:5])
+	f.Close()
+	s, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var keys []string
+	s.Scan("", func(k string, v []byte) error {
+		keys = append(keys, k)
+		return nil
+	})
+	if want := "b/1 c"; strings.Join(keys, " ") != want {
+		t.Fatalf("unexpected keys: want %q, have %q", want, keys)
+	}
+	if v, _ := s.Get("c"); string(v) != "foobar" {
+		t.Fatalf("unexpected value: want foobar, have %q", v)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTrackerTTL is the time submitted messages are kept by a
+// ReceiptTracker when its TTL is not set.
+const DefaultTrackerTTL = 72 * time.Hour
+
+// trackerPrefix is the prefix of the ReceiptTracker keys in its Store.
+const trackerPrefix = "receipt/"
+
+// TrackerHandlerFunc is the handler function that a ReceiptTracker
+// calls when a delivery receipt matches a submitted message.
+type TrackerHandlerFunc func(sm *ShortMessage, dr *DeliveryReceipt)
+
+// ReceiptTracker correlates delivery receipts with the messages
+// submitted by a Transmitter or Transceiver.
+//
+// The same ReceiptTracker can be set on a Transmitter and a Receiver
+// to match the receipts of messages submitted over another connection.
+// Message IDs are compared ignoring case and leading zeros. SMSCs that
+// report IDs in hex in one PDU and decimal in the other are supported
+// by setting IDFormat.
+//
+// Messages waiting for receipts are kept in the Store. After a restart,
+// the messages read back from a persistent Store only have the Src, Dst
+// and Metadata fields set, with Metadata decoded from JSON.
+//
+// Since messages are tracked from the goroutine reading the connection,
+// writes to the Store and the removal of expired messages are done in
+// the background. Flush waits for pending writes.
+type ReceiptTracker struct {
+	TTL     time.Duration      // Time to keep submitted messages, default 72h.
+	Handler TrackerHandlerFunc // Called for matched receipts.
+	Store   Store              // Storage of submitted messages, default in memory.
+
+	// IDFormat is the format of message IDs used by the SMSC in
+	// submit_sm_resp and delivery receipts, default SameIDFormat.
+	IDFormat IDFormat
+
+	mu        sync.Mutex
+	once      sync.Once
+	flushed   *sync.Cond            // signaled when writing is done
+	sms       map[string]*trackedSM // messages tracked by this process
+	forgotten map[string]int        // pending deletes from the Store
+	ops       []trackerOp           // pending writes to the Store, in order
+	writing   bool                  // ops are being written
+	expiring  bool                  // expired messages are being removed
+	expired   time.Time             // last time expired messages were removed
+}
+
+// trackedSM is a message tracked by this process.
+type trackedSM struct {
+	sm   *ShortMessage
+	time time.Time
+}
+
+// trackerOp is a write to the Store: a Put, or a Delete if value is nil.
+type trackerOp struct {
+	id    string
+	value []byte
+}
+
+// IDFormat describes how the SMSC formats the message ID of the same
+// message in submit_sm_resp and in its delivery receipts.
+type IDFormat uint8
+
+// Supported ID formats.
+const (
+	SameIDFormat      IDFormat = iota // Same ID in both PDUs.
+	HexRespDecReceipt                 // Hex in submit_sm_resp, decimal in receipts.
+	DecRespHexReceipt                 // Decimal in submit_sm_resp, hex in receipts.
+)
+
+// trackedMsg is the record of a submitted message in the Store.
+type trackedMsg struct {
+	Time     time.Time   `json:"time"`
+	Src      string      `json:"src,omitempty"`
+	Dst      string      `json:"dst,omitempty"`
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
+func (rt *ReceiptTracker) init() {
+	rt.once.Do(func() {
+		if rt.Store == nil {
+			rt.Store = NewMemoryStore()
+		}
+		rt.flushed = sync.NewCond(&rt.mu)
+		rt.sms = make(map[string]*trackedSM)
+		rt.forgotten = make(map[string]int)
+	})
+}
+
+func (rt *ReceiptTracker) ttl() time.Duration {
+	if rt.TTL == 0 {
+		return DefaultTrackerTTL
+	}
+	return rt.TTL
+}
+
+// Track records the message ID returned by the SMSC for sm. It is
+// called by the Transmitter for every successful submission, including
+// each part submitted by SubmitLongMsg.
+//
+// The message is written to the Store in the background, and matches
+// receipts right away.
+func (rt *ReceiptTracker) Track(msgid string, sm *ShortMessage) error {
+	rt.init()
+	id := canonicalMsgID(msgid)
+	if id == "" {
+		return nil
+	}
+	now := time.Now()
+	b, err := json.Marshal(&trackedMsg{
+		Time:     now,
+		Src:      sm.Src,
+		Dst:      sm.Dst,
+		Metadata: sm.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	rt.mu.Lock()
+	rt.sms[id] = &trackedSM{sm: sm, time: now}
+	rt.queueLocked(trackerOp{id: id, value: b})
+	expire := !rt.expiring && now.Sub(rt.expired) > rt.ttl()/10
+	if expire {
+		rt.expiring = true
+		rt.expired = now
+	}
+	rt.mu.Unlock()
+	if expire {
+		go func() {
+			rt.expire(now)
+			rt.mu.Lock()
+			rt.expiring = false
+			rt.mu.Unlock()
+		}()
+	}
+	return nil
+}
+
+// Match looks up the submitted message of the given receipt, and calls
+// Handler if found. The message is forgotten once a final receipt
+// arrives. It returns false if the receipt does not match any message.
+func (rt *ReceiptTracker) Match(dr *DeliveryReceipt) bool {
+	rt.init()
+	id, m := rt.lookup(dr.ID)
+	if m == nil {
+		return false
+	}
+	if time.Since(m.time) > rt.ttl() {
+		rt.forget(id)
+		return false
+	}
+	if !dr.Intermediate && dr.State != 1 { // ENROUTE
+		rt.forget(id)
+	}
+	if rt.Handler != nil {
+		rt.Handler(m.sm, dr)
+	}
+	return true
+}
+
+// Len returns the number of messages waiting for receipts.
+func (rt *ReceiptTracker) Len() int {
+	rt.init()
+	rt.expire(time.Now())
+	ids := make(map[string]bool)
+	rt.Store.Scan(trackerPrefix, func(k string, v []byte) error {
+		ids[strings.TrimPrefix(k, trackerPrefix)] = true
+		return nil
+	})
+	rt.mu.Lock()
+	for id := range rt.forgotten {
+		delete(ids, id)
+	}
+	for id := range rt.sms {
+		ids[id] = true
+	}
+	rt.mu.Unlock()
+	return len(ids)
+}
+
+// Flush waits for the pending writes to the Store.
+func (rt *ReceiptTracker) Flush() {
+	rt.init()
+	rt.mu.Lock()
+	for rt.writing {
+		rt.flushed.Wait()
+	}
+	rt.mu.Unlock()
+}
+
+// lookup returns the canonical ID and message of the given receipt
+// message ID, converted to the format of submit_sm_resp according
+// to IDFormat.
+func (rt *ReceiptTracker) lookup(msgid string) (string, *trackedSM) {
+	id := canonicalMsgID(msgid)
+	switch rt.IDFormat {
+	case HexRespDecReceipt:
+		if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+			id = strconv.FormatUint(n, 16)
+		}
+	case DecRespHexReceipt:
+		if n, err := strconv.ParseUint(id, 16, 64); err == nil {
+			id = strconv.FormatUint(n, 10)
+		}
+	}
+	rt.mu.Lock()
+	m, forgotten := rt.sms[id], rt.forgotten[id] > 0
+	rt.mu.Unlock()
+	if m != nil {
+		return id, m
+	}
+	if forgotten {
+		return "", nil
+	}
+	b, err := rt.Store.Get(trackerPrefix + id)
+	if err != nil || b == nil {
+		return "", nil
+	}
+	var tm trackedMsg
+	if json.Unmarshal(b, &tm) != nil {
+		return "", nil
+	}
+	sm := &ShortMessage{Src: tm.Src, Dst: tm.Dst, Metadata: tm.Metadata}
+	return id, &trackedSM{sm: sm, time: tm.Time}
+}
+
+func (rt *ReceiptTracker) forget(id string) {
+	rt.mu.Lock()
+	rt.forgetLocked(id)
+	rt.mu.Unlock()
+}
+
+func (rt *ReceiptTracker) forgetLocked(id string) {
+	delete(rt.sms, id)
+	rt.forgotten[id]++
+	rt.queueLocked(trackerOp{id: id})
+}
+
+// queueLocked adds op to the pending writes, and starts writing them
+// if not running. It must be called with rt.mu locked.
+func (rt *ReceiptTracker) queueLocked(op trackerOp) {
+	rt.ops = append(rt.ops, op)
+	if !rt.writing {
+		rt.writing = true
+		go rt.write()
+	}
+}
+
+// write applies the pending writes to the Store in order, until there
+// are none left.
+func (rt *ReceiptTracker) write() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for len(rt.ops) > 0 {
+		op := rt.ops[0]
+		rt.ops = rt.ops[1:]
+		rt.mu.Unlock()
+		if op.value != nil {
+			rt.Store.Put(trackerPrefix+op.id, op.value)
+		} else {
+			rt.Store.Delete(trackerPrefix + op.id)
+		}
+		rt.mu.Lock()
+		if op.value == nil {
+			if rt.forgotten[op.id]--; rt.forgotten[op.id] <= 0 {
+				delete(rt.forgotten, op.id)
+			}
+		}
+	}
+	rt.ops = nil
+	rt.writing = false
+	rt.flushed.Broadcast()
+}
+
+// expire removes messages older than the TTL.
+func (rt *ReceiptTracker) expire(now time.Time) {
+	ttl := rt.ttl()
+	rt.mu.Lock()
+	for id, m := range rt.sms {
+		if now.Sub(m.time) > ttl {
+			rt.forgetLocked(id)
+		}
+	}
+	rt.mu.Unlock()
+	rt.Store.Scan(trackerPrefix, func(k string, v []byte) error {
+		var m trackedMsg
+		if json.Unmarshal(v, &m) == nil && now.Sub(m.Time) <= ttl {
+			return nil
+		}
+		id := strings.TrimPrefix(k, trackerPrefix)
+		rt.mu.Lock()
+		if tm := rt.sms[id]; tm == nil || now.Sub(tm.time) > ttl {
+			rt.forgetLocked(id)
+		}
+		rt.mu.Unlock()
+		return nil
+	})
+}
+
+// canonicalMsgID returns msgid in lower case without leading zeros.
+func canonicalMsgID(msgid string) string {
+	id := strings.ToLower(strings.TrimSpace(msgid))
+	if t := strings.TrimLeft(id, "0"); t != "" {
+		return t
+	}
+	return id
+}
@@ -0,0 +1,221 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fiorix/go-smpp/smpp/pdu"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/smpptest"
+)
+
+func TestReceiptTrackerMatch(t *testing.T) {
+	var matched []*ShortMessage
+	rt := &ReceiptTracker{
+		Handler: func(sm *ShortMessage, dr *DeliveryReceipt) {
+			matched = append(matched, sm)
+		},
+	}
+	test := []struct {
+		respID    string
+		receiptID string
+	}{
+		{"abc123", "ABC123"},
+		{"000042", "42"},
+		{"100", "256"},    // no match, hex and decimal are not mixed
+		{"foobar", "foo"}, // no match
+	}
+	for i, tc := range test {
+		rt.Track(tc.respID, &ShortMessage{Metadata: i})
+	}
+	for i, tc := range test {
+		ok := rt.Match(&DeliveryReceipt{ID: tc.receiptID, State: 2})
+		if want := i < 2; ok != want {
+			t.Fatalf("unexpected match for %q: want %t, have %t", tc.receiptID, want, ok)
+		}
+		if ok && matched[len(matched)-1].Metadata != i {
+			t.Fatalf("unexpected message for %q: %#v", tc.receiptID, matched[len(matched)-1])
+		}
+	}
+	if n := rt.Len(); n != 2 {
+		t.Fatalf("unexpected # of tracked messages: want 2, have %d", n)
+	}
+}
+
+func TestReceiptTrackerIDFormat(t *testing.T) {
+	test := []struct {
+		format    IDFormat
+		respID    string
+		receiptID string
+		match     bool
+	}{
+		{HexRespDecReceipt, "1A2B", "6699", true},
+		{HexRespDecReceipt, "100", "100", false},
+		{HexRespDecReceipt, "10", "16", true},
+		{DecRespHexReceipt, "6700", "1A2C", true},
+		{DecRespHexReceipt, "16", "16", false},
+		{DecRespHexReceipt, "256", "100", true},
+	}
+	for _, tc := range test {
+		rt := &ReceiptTracker{IDFormat: tc.format}
+		rt.Track(tc.respID, &ShortMessage{})
+		if ok := rt.Match(&DeliveryReceipt{ID: tc.receiptID, State: 2}); ok != tc.match {
+			t.Fatalf("unexpected match of %q with %q: want %t, have %t",
+				tc.receiptID, tc.respID, tc.match, ok)
+		}
+	}
+}
+
+func TestReceiptTrackerExpire(t *testing.T) {
+	rt := &ReceiptTracker{TTL: 50 * time.Millisecond}
+	rt.Track("1", &ShortMessage{})
+	if !rt.Match(&DeliveryReceipt{ID: "1", State: 1, Intermediate: true}) {
+		t.Fatal("intermediate receipt not matched")
+	}
+	if n := rt.Len(); n != 1 {
+		t.Fatalf("unexpected # of tracked messages: want 1, have %d", n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if rt.Match(&DeliveryReceipt{ID: "1", State: 2}) {
+		t.Fatal("expired message matched")
+	}
+	if n := rt.Len(); n != 0 {
+		t.Fatalf("unexpected # of tracked messages: want 0, have %d", n)
+	}
+}
+
+func TestReceiptTrackerStore(t *testing.T) {
+	store := NewMemoryStore()
+	rt := &ReceiptTracker{Store: store}
+	if err := rt.Track("42", &ShortMessage{Src: "root", Metadata: "foobar"}); err != nil {
+		t.Fatal(err)
+	}
+	rt.Flush()
+	// New tracker with the same store, as after a restart.
+	var have *ShortMessage
+	rt = &ReceiptTracker{
+		Store:   store,
+		Handler: func(sm *ShortMessage, dr *DeliveryReceipt) { have = sm },
+	}
+	if !rt.Match(&DeliveryReceipt{ID: "42", State: 2}) {
+		t.Fatal("receipt not matched")
+	}
+	if have.Src != "root" || have.Metadata != "foobar" {
+		t.Fatalf("unexpected message: %#v", have)
+	}
+	rt.Flush()
+	if v, _ := store.Get(trackerPrefix + "42"); v != nil {
+		t.Fatalf("message not removed from store: %q", v)
+	}
+}
+
+// slowStore is a Store with slow writes.
+type slowStore struct {
+	Store
+	delay time.Duration
+}
+
+func (s *slowStore) Put(key string, value []byte) error {
+	time.Sleep(s.delay)
+	return s.Store.Put(key, value)
+}
+
+func (s *slowStore) Delete(key string) error {
+	time.Sleep(s.delay)
+	return s.Store.Delete(key)
+}
+
+func TestReceiptTrackerSlowStore(t *testing.T) {
+	store := NewMemoryStore()
+	rt := &ReceiptTracker{Store: &slowStore{Store: store, delay: 100 * time.Millisecond}}
+	start := time.Now()
+	rt.Track("1", &ShortMessage{})
+	rt.Track("2", &ShortMessage{})
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("Track blocked on the store for %s", d)
+	}
+	if !rt.Match(&DeliveryReceipt{ID: "1", State: 2}) {
+		t.Fatal("receipt not matched before the store write")
+	}
+	if rt.Match(&DeliveryReceipt{ID: "1", State: 2}) {
+		t.Fatal("receipt matched twice")
+	}
+	rt.Flush()
+	if v, _ := store.Get(trackerPrefix + "1"); v != nil {
+		t.Fatalf("message not removed from store: %q", v)
+	}
+	if v, _ := store.Get(trackerPrefix + "2"); v == nil {
+		t.Fatal("message not written to store")
+	}
+	if n := rt.Len(); n != 1 {
+		t.Fatalf("unexpected # of tracked messages: want 1, have %d", n)
+	}
+}
+
+func TestTransceiverTracker(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "FF")
+			c.Write(r)
+			dr := pdu.NewDeliverSM()
+			f := dr.Fields()
+			f.Set(pdufield.SourceAddr, "foobar")
+			f.Set(pdufield.DestinationAddr, "root")
+			f.Set(pdufield.ESMClass, 0x04)
+			f.Set(pdufield.ShortMessage, "id:255 sub:001 dlvrd:001 stat:DELIVRD err:000 text:")
+			c.Write(dr)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	type match struct {
+		sm *ShortMessage
+		dr *DeliveryReceipt
+	}
+	mc := make(chan match, 1)
+	tc := &Transceiver{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+		Tracker: &ReceiptTracker{
+			IDFormat: HexRespDecReceipt,
+			Handler:  func(sm *ShortMessage, dr *DeliveryReceipt) { mc <- match{sm, dr} },
+		},
+	}
+	defer tc.Close()
+	conn := <-tc.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{
+		Src:      "root",
+		Dst:      "foobar",
+		Text:     pdutext.Raw("Lorem ipsum"),
+		Register: pdufield.FinalDeliveryReceipt,
+		Metadata: "foobar",
+	}
+	if _, err := tc.Submit(sm); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-mc:
+		if m.sm != sm || m.dr.ID != "255" || !m.dr.Delivered() {
+			t.Fatalf("unexpected match: %#v %#v", m.sm, m.dr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for receipt")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"math/rand"
+	"net"
 	"time"
 
 	"github.com/fiorix/go-smpp/smpp/pdu"
@@ -18,44 +19,91 @@ import (
 //
 // The API is a combination of the Transmitter and Receiver.
 type Transceiver struct {
-	Addr               string        // Server address in form of host:port.
-	User               string        // Username.
-	Passwd             string        // Password.
-	SystemType         string        // System type, default empty.
-	EnquireLink        time.Duration // Enquire link interval, default 10s.
-	EnquireLinkTimeout time.Duration // Time after last EnquireLink response when connection considered down
-	RespTimeout        time.Duration // Response timeout, default 1s.
-	BindInterval       time.Duration // Binding retry interval
-	TLS                *tls.Config   // TLS client settings, optional.
-	Handler            HandlerFunc   // Receiver handler, optional.
-	RateLimiter        RateLimiter   // Rate limiter, optional.
-	WindowSize         uint
+	Addr               string             // Server address in form of host:port.
+	User               string             // Username.
+	Passwd             string             // Password.
+	SystemType         string             // System type, default empty.
+	EnquireLink        time.Duration      // Enquire link interval, default 10s.
+	EnquireLinkTimeout time.Duration      // Time after last EnquireLink response when connection considered down
+	RespTimeout        time.Duration      // Response timeout, default 1s.
+	BindInterval       time.Duration      // Binding retry interval
+	TLS                *tls.Config        // TLS client settings, optional.
+	Handler            HandlerFunc        // Receiver handler, optional.
+	AlertHandler       AlertHandlerFunc   // AlertNotification handler, optional.
+	ReceiptHandler     ReceiptHandlerFunc // Delivery receipt handler, optional.
+	Tracker            *ReceiptTracker    // Delivery receipt tracker, optional.
+	RateLimiter        RateLimiter        // Rate limiter, optional.
+	WindowSize         uint               // Max # of requests waiting for response, optional.
+	WindowWait         bool               // Wait for a free window slot rather than fail with ErrMaxWindowSize.
 
 	UnknownPDUDecoder UnknownPDUDecoder
 
+	// Credentials expected in the Outbind PDU when the Transceiver
+	// is started with Listen.
+	OutbindSystemID string
+	OutbindPasswd   string
+	OutbindTLS      *tls.Config   // TLS server settings of the Outbind listener, optional.
+	OutbindTimeout  time.Duration // Time to wait for the Outbind after accepting a connection, default 10s.
+
 	Transmitter
+
+	ob *outbindListener
 }
 
 // Bind implements the ClientConn interface.
 func (t *Transceiver) Bind() <-chan ConnStatus {
-	t.r = rand.New(rand.NewSource(time.Now().UnixNano()))
 	t.cl.Lock()
 	defer t.cl.Unlock()
+	return t.bind(nil)
+}
+
+// Listen starts the Transceiver for SMSC initiated sessions, as
+// Receiver.Listen does, but binds as transceiver on the connection
+// of the Outbind. The listener is closed when Close is called.
+func (t *Transceiver) Listen(l net.Listener) <-chan ConnStatus {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client != nil {
+		return t.cl.Status
+	}
+	t.ob = newOutbindListener(l, t.OutbindTLS, t.OutbindSystemID, t.OutbindPasswd, t.OutbindTimeout)
+	return t.bind(t.ob.accept)
+}
+
+// Close implements the ClientConn interface.
+func (t *Transceiver) Close() error {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client == nil {
+		return ErrNotConnected
+	}
+	if t.ob != nil {
+		t.ob.Close()
+	}
+	return t.cl.Close()
+}
+
+// bind must be called with t.cl locked.
+func (t *Transceiver) bind(dial func() (Conn, error)) <-chan ConnStatus {
+	t.r = rand.New(rand.NewSource(time.Now().UnixNano()))
 	if t.cl.client != nil {
 		return t.cl.Status
 	}
 	t.tx.Lock()
-	t.tx.inflight = make(map[string]chan *tx)
+	t.tx.inflight = make(map[string]func(*tx))
 	t.tx.Unlock()
 	c := &client{
 		Addr:               t.Addr,
 		TLS:                t.TLS,
 		Status:             make(chan ConnStatus, 1),
+		DialFunc:           dial,
 		BindFunc:           t.bindFunc,
 		EnquireLink:        t.EnquireLink,
 		EnquireLinkTimeout: t.EnquireLinkTimeout,
 		RespTimeout:        t.RespTimeout,
 		WindowSize:         t.WindowSize,
+		WindowWait:         t.WindowWait,
+		Tracker:            t.Tracker,
 		RateLimiter:        t.RateLimiter,
 		BindInterval:       t.BindInterval,
 		UnknownPDUDecoder:  t.UnknownPDUDecoder,
@@ -80,6 +128,21 @@ func (t *Transceiver) bindFunc(c Conn) error {
 		return fmt.Errorf("unexpected response for BindTransceiver: %s",
 			resp.Header().ID)
 	}
-	go t.handlePDU(t.Handler)
+	go t.handlePDU(t.handler)
 	return nil
 }
+
+// handler dispatches incoming PDUs to AlertHandler, Tracker,
+// ReceiptHandler or Handler.
+func (t *Transceiver) handler(p pdu.Body) {
+	if p.Header().ID == pdu.AlertNotificationID && t.AlertHandler != nil {
+		t.AlertHandler(newAlert(p))
+		return
+	}
+	if handleReceipt(p, t.Tracker, t.ReceiptHandler) {
+		return
+	}
+	if t.Handler != nil {
+		t.Handler(p)
+	}
+}
@@ -6,6 +6,7 @@ package smpp
 
 import (
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -89,3 +90,52 @@ func TestTransceiver(t *testing.T) {
 		t.Fatal("timeout waiting for ack")
 	}
 }
+
+func TestTransceiverOutbind(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &Transceiver{
+		User:            smpptest.DefaultUser,
+		Passwd:          smpptest.DefaultPasswd,
+		OutbindSystemID: smpptest.DefaultSystemID,
+		OutbindPasswd:   smpptest.DefaultPasswd,
+	}
+	defer tc.Close()
+	status := tc.Listen(l)
+	if err = s.Outbind(l.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	conn := <-status
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm, err := tc.Submit(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgid := sm.RespID(); msgid != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", msgid)
+	}
+}
@@ -5,6 +5,7 @@
 package smpp
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"errors"
@@ -12,36 +13,49 @@ import (
 	"math/rand"
 	"strconv"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/fiorix/go-smpp/smpp/pdu"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/smpp/pdu/pduudh"
 )
 
 // ErrMaxWindowSize is returned when an operation (such as Submit) violates
 // the maximum window size configured for the Transmitter or Transceiver.
+// When WindowWait is set, it is returned if no window slot becomes free
+// within the response timeout.
 var ErrMaxWindowSize = errors.New("reached max window size")
 
+// ErrTooManyParts is returned by SubmitLongMsg when the message needs
+// more than MaxLongMsgParts parts.
+var ErrTooManyParts = errors.New("long message has too many parts")
+
+// MaxLongMsgParts is the maximum number of parts of a long message
+// concatenated with UDH or SAR TLVs, which hold 8-bit part numbers.
+const MaxLongMsgParts = 255
+
 // MaxDestinationAddress is the maximum number of destination addresses allowed
 // in the submit_multi operation.
 const MaxDestinationAddress = 254
 
 // Transmitter implements an SMPP client transmitter.
 type Transmitter struct {
-	Addr               string        // Server address in form of host:port.
-	User               string        // Username.
-	Passwd             string        // Password.
-	SystemType         string        // System type, default empty.
-	EnquireLink        time.Duration // Enquire link interval, default 10s.
-	EnquireLinkTimeout time.Duration // Time after last EnquireLink response when connection considered down
-	RespTimeout        time.Duration // Response timeout, default 1s.
-	BindInterval       time.Duration // Binding retry interval
-	TLS                *tls.Config   // TLS client settings, optional.
-	RateLimiter        RateLimiter   // Rate limiter, optional.
-	WindowSize         uint
+	Addr               string          // Server address in form of host:port.
+	User               string          // Username.
+	Passwd             string          // Password.
+	SystemType         string          // System type, default empty.
+	EnquireLink        time.Duration   // Enquire link interval, default 10s.
+	EnquireLinkTimeout time.Duration   // Time after last EnquireLink response when connection considered down
+	RespTimeout        time.Duration   // Response timeout, default 1s.
+	BindInterval       time.Duration   // Binding retry interval
+	TLS                *tls.Config     // TLS client settings, optional.
+	RateLimiter        RateLimiter     // Rate limiter, optional.
+	WindowSize         uint            // Max # of requests waiting for response, optional.
+	WindowWait         bool            // Wait for a free window slot rather than fail with ErrMaxWindowSize.
+	Tracker            *ReceiptTracker // Delivery receipt tracker, optional.
+	Split              SplitStrategy   // How SubmitLongMsg splits long messages, default UDH with 16-bit reference.
 	rMutex             sync.Mutex
 	r                  *rand.Rand
 
@@ -51,9 +65,8 @@ type Transmitter struct {
 	}
 
 	tx struct {
-		count int32
 		sync.Mutex
-		inflight map[string]chan *tx
+		inflight map[string]func(*tx)
 	}
 }
 
@@ -74,7 +87,7 @@ func (t *Transmitter) Bind() <-chan ConnStatus {
 		return t.cl.Status
 	}
 	t.tx.Lock()
-	t.tx.inflight = make(map[string]chan *tx)
+	t.tx.inflight = make(map[string]func(*tx))
 	t.tx.Unlock()
 	c := &client{
 		Addr:               t.Addr,
@@ -85,6 +98,8 @@ func (t *Transmitter) Bind() <-chan ConnStatus {
 		EnquireLinkTimeout: t.EnquireLinkTimeout,
 		RespTimeout:        t.RespTimeout,
 		WindowSize:         t.WindowSize,
+		WindowWait:         t.WindowWait,
+		Tracker:            t.Tracker,
 		RateLimiter:        t.RateLimiter,
 		BindInterval:       t.BindInterval,
 	}
@@ -119,13 +134,7 @@ func (t *Transmitter) handlePDU(f HandlerFunc) {
 		if err != nil || p == nil {
 			break
 		}
-		key := p.Header().Key()
-		t.tx.Lock()
-		rc := t.tx.inflight[key]
-		t.tx.Unlock()
-		if rc != nil {
-			rc <- &tx{PDU: p}
-		} else if f != nil {
+		if !t.finish(p.Header().Key(), &tx{PDU: p}) && f != nil {
 			f(p)
 		}
 		if p.Header().ID == pdu.DeliverSMID { // Send DeliverSMResp
@@ -139,17 +148,17 @@ func (t *Transmitter) handlePDU(f HandlerFunc) {
 					messageID = v.String()
 				}
 			}
-			if messageID != "" {
-				pResp := pdu.NewDataSMResp(p.Header().Seq, messageID)
-				t.cl.Write(pResp)
-			}
+			pResp := pdu.NewDataSMResp(p.Header().Seq, messageID)
+			t.cl.Write(pResp)
 		}
 	}
 	t.tx.Lock()
-	for _, rc := range t.tx.inflight {
-		rc <- &tx{Err: ErrNotConnected}
-	}
+	inflight := t.tx.inflight
+	t.tx.inflight = make(map[string]func(*tx))
 	t.tx.Unlock()
+	for _, done := range inflight {
+		done(&tx{Err: ErrNotConnected})
+	}
 }
 
 // Close implements the ClientConn interface.
@@ -181,6 +190,18 @@ func newUnsucessDest(p pdufield.UnSme) UnsucessDest {
 	return unDest
 }
 
+// SplitStrategy defines how SubmitLongMsg splits long messages.
+type SplitStrategy uint8
+
+// Supported split strategies.
+const (
+	SplitDefault SplitStrategy = iota // Use the strategy of the Transmitter, or SplitUDH16.
+	SplitUDH16                        // UDH with 16-bit reference number (IEI 0x08).
+	SplitUDH8                         // UDH with 8-bit reference number (IEI 0x00).
+	SplitSAR                          // sar_msg_ref_num, sar_total_segments and sar_segment_seqnum TLVs.
+	SplitPayload                      // Single SubmitSM with the message_payload TLV.
+)
+
 // ShortMessage configures a short message that can be submitted via
 // the Transmitter. When returned from Submit, the ShortMessage
 // provides Resp and RespID.
@@ -207,6 +228,21 @@ type ShortMessage struct {
 	ReplaceIfPresentFlag uint8
 	SMDefaultMsgID       uint8
 	NumberDests          uint8
+	Split                SplitStrategy // How SubmitLongMsg splits the message, optional.
+
+	// UDH sets information elements of the user data header, e.g.
+	// application ports. They're sent in every part of long messages,
+	// along with the concatenation element. Optional.
+	UDH pduudh.UDH
+
+	// DCS sets the data coding scheme of the message, e.g. to send
+	// flash messages or message waiting indications. Its alphabet
+	// is taken from the Text codec. Optional.
+	DCS *pdutext.DCS
+
+	// Metadata is not sent to the SMSC. It can be used to identify
+	// the message in the Tracker handler.
+	Metadata interface{}
 
 	resp struct {
 		sync.Mutex
@@ -282,31 +318,103 @@ func (sm *ShortMessage) UnsuccessSmes() ([]UnsucessDest, error) {
 	return nil, errors.New("Cannot convert PDU field to UnSmeList")
 }
 
-func (t *Transmitter) do(p pdu.Body) (*tx, error) {
+// WindowStats returns the usage of the window of inflight requests.
+// It returns zero values if not bound or WindowSize is not set.
+func (t *Transmitter) WindowStats() WindowStats {
+	t.cl.Lock()
+	defer t.cl.Unlock()
+	if t.cl.client == nil || t.cl.window == nil {
+		return WindowStats{}
+	}
+	return t.cl.window.Stats()
+}
+
+// send registers done to be called with the response of p, and
+// writes p. The done function is called exactly once, with the response
+// or with an error when the response timeout fires or the connection is
+// lost, unless send returns an error.
+func (t *Transmitter) send(ctx context.Context, p pdu.Body, done func(*tx)) error {
 	t.cl.Lock()
 	notbound := t.cl.client == nil
 	t.cl.Unlock()
 	if notbound {
-		return nil, ErrNotBound
+		return ErrNotBound
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	if t.cl.WindowSize > 0 {
-		inflight := uint(atomic.AddInt32(&t.tx.count, 1))
-		defer func(t *Transmitter) { atomic.AddInt32(&t.tx.count, -1) }(t)
-		if inflight > t.cl.WindowSize {
-			return nil, ErrMaxWindowSize
+	release := func() {}
+	if w := t.cl.window; w != nil {
+		if t.cl.WindowWait {
+			// Wait in line for a slot, for up to the response timeout.
+			if err := w.acquire(ctx, t.cl.respTimeout()); err != nil {
+				return err
+			}
+		} else if !w.tryAcquire() {
+			return ErrMaxWindowSize
 		}
+		release = w.release
 	}
-	rc := make(chan *tx, 1)
+	var timer *time.Timer
 	key := p.Header().Key()
 	t.tx.Lock()
-	t.tx.inflight[key] = rc
+	t.tx.inflight[key] = func(r *tx) {
+		if timer != nil {
+			timer.Stop()
+		}
+		release()
+		done(r)
+	}
 	t.tx.Unlock()
-	defer func() {
-		t.tx.Lock()
+	err := t.cl.writeContext(ctx, p)
+	t.tx.Lock()
+	defer t.tx.Unlock()
+	if _, ok := t.tx.inflight[key]; !ok {
+		// Already finished, e.g. the connection was lost.
+		return nil
+	}
+	if err != nil {
 		delete(t.tx.inflight, key)
-		t.tx.Unlock()
-	}()
-	err := t.cl.Write(p)
+		release()
+		return err
+	}
+	timer = time.AfterFunc(t.cl.respTimeoutDuration(), func() {
+		t.finish(key, &tx{Err: ErrTimeout})
+	})
+	return nil
+}
+
+// finish removes the inflight request with the given key and calls its
+// done function with r. It returns false if there's no such request.
+func (t *Transmitter) finish(key string, r *tx) bool {
+	t.tx.Lock()
+	done := t.tx.inflight[key]
+	delete(t.tx.inflight, key)
+	t.tx.Unlock()
+	if done == nil {
+		return false
+	}
+	done(r)
+	return true
+}
+
+// do sends p and waits for its response, the response timeout, or
+// ctx to be done, whichever happens first.
+func (t *Transmitter) do(ctx context.Context, p pdu.Body) (*tx, error) {
+	return t.doTrack(ctx, p, nil)
+}
+
+// doTrack is like do but also tracks the message ID of the response
+// for sm, if sm is not nil. The ID is tracked before the next PDU is
+// read, in case the SMSC sends the receipt right after the response.
+func (t *Transmitter) doTrack(ctx context.Context, p pdu.Body, sm *ShortMessage) (*tx, error) {
+	rc := make(chan *tx, 1)
+	err := t.send(ctx, p, func(r *tx) {
+		if sm != nil && r.Err == nil {
+			t.track(r.PDU, sm)
+		}
+		rc <- r
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -316,85 +424,161 @@ func (t *Transmitter) do(p pdu.Body) (*tx, error) {
 			return nil, resp.Err
 		}
 		return resp, nil
-	case <-t.cl.respTimeout():
-		return nil, ErrTimeout
+	case <-ctx.Done():
+		t.finish(p.Header().Key(), &tx{Err: ctx.Err()})
+		return nil, ctx.Err()
 	}
 }
 
 // Submit sends a short message and returns and updates the given
 // sm with the response status. It returns the same sm object.
 func (t *Transmitter) Submit(sm *ShortMessage) (*ShortMessage, error) {
+	return t.SubmitContext(context.Background(), sm)
+}
+
+// SubmitContext is like Submit but takes a context that can cancel
+// the rate limiter wait, the wait for a free window slot when WindowWait
+// is set, and the wait for the response. If ctx is done first, ctx.Err()
+// is returned.
+func (t *Transmitter) SubmitContext(ctx context.Context, sm *ShortMessage) (*ShortMessage, error) {
+	p, err := newSubmit(sm)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.doTrack(ctx, p, sm)
+	if err != nil {
+		return nil, err
+	}
+	return submitResp(sm, p, resp)
+}
+
+// SubmitAsync sends a short message without waiting for the response.
+// The callback cb is called with the given sm updated with the response,
+// or with an error if the response timeout fires or the connection is
+// lost. Callbacks are called from the goroutine reading the connection
+// and should not block.
+//
+// Up to WindowSize messages can be waiting for a response. When the
+// window is full SubmitAsync returns ErrMaxWindowSize, or blocks until
+// a slot is free if WindowWait is set. If SubmitAsync returns an error,
+// cb is not called.
+func (t *Transmitter) SubmitAsync(sm *ShortMessage, cb func(*ShortMessage, error)) error {
+	return t.SubmitAsyncContext(context.Background(), sm, cb)
+}
+
+// SubmitAsyncContext is like SubmitAsync but takes a context that can
+// cancel the rate limiter wait and the wait for a free window slot.
+// Once SubmitAsyncContext returns, ctx has no effect on the response.
+func (t *Transmitter) SubmitAsyncContext(ctx context.Context, sm *ShortMessage, cb func(*ShortMessage, error)) error {
+	p, err := newSubmit(sm)
+	if err != nil {
+		return err
+	}
+	return t.send(ctx, p, func(resp *tx) {
+		if resp.Err != nil {
+			cb(nil, resp.Err)
+			return
+		}
+		sm, err := submitResp(sm, p, resp)
+		if err == nil {
+			t.track(resp.PDU, sm)
+		}
+		cb(sm, err)
+	})
+}
+
+// newSubmit returns a SubmitSM, or SubmitMulti for messages with
+// multiple destinations, with the fields set from sm.
+func newSubmit(sm *ShortMessage) (pdu.Body, error) {
+	dataCoding, err := smDataCoding(sm)
+	if err != nil {
+		return nil, err
+	}
 	if len(sm.DstList) > 0 || len(sm.DLs) > 0 {
 		// if we have a single destination address add it to the list
 		if sm.Dst != "" {
 			sm.DstList = append(sm.DstList, sm.Dst)
 		}
 		p := pdu.NewSubmitMulti(sm.TLVFields)
-		return t.submitMsgMulti(sm, p, uint8(sm.Text.Type()))
+		return p, setSubmitMultiFields(sm, p, dataCoding)
 	}
 	p := pdu.NewSubmitSM(sm.TLVFields)
-	return t.submitMsg(sm, p, uint8(sm.Text.Type()))
+	return p, setSubmitFields(sm, p, dataCoding)
+}
+
+// submitResp updates sm with the response of the submit request p.
+func submitResp(sm *ShortMessage, p pdu.Body, resp *tx) (*ShortMessage, error) {
+	sm.resp.Lock()
+	sm.resp.p = resp.PDU
+	sm.resp.Unlock()
+	if resp.PDU == nil {
+		return nil, fmt.Errorf("unexpected empty PDU")
+	}
+	want := pdu.SubmitSMRespID
+	if p.Header().ID == pdu.SubmitMultiID {
+		want = pdu.SubmitMultiRespID
+	}
+	if id := resp.PDU.Header().ID; id != want {
+		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return sm, s
+	}
+	return sm, resp.Err
+}
+
+// track records the message ID of the response p, if the Tracker is set
+// and the response status is ok.
+func (t *Transmitter) track(p pdu.Body, sm *ShortMessage) {
+	if t.cl.Tracker == nil || p == nil || p.Header().Status != 0 {
+		return
+	}
+	if f := p.Fields()[pdufield.MessageID]; f != nil {
+		t.cl.Tracker.Track(f.String(), sm)
+	}
 }
 
 // SubmitLongMsg sends a long message (more than 140 bytes)
 // and returns and updates the given sm with the response status.
 // It returns the same sm object.
+//
+// The message is split as defined by the Split field of sm, or of the
+// Transmitter when not set. Messages that need more than MaxLongMsgParts
+// parts return ErrTooManyParts, and no part is sent.
 func (t *Transmitter) SubmitLongMsg(sm *ShortMessage) ([]ShortMessage, error) {
-	maxLen := 133 // 140-7 (UDH with 2 byte reference number)
-	switch sm.Text.(type) {
-	case pdutext.GSM7:
-		maxLen = 152 // to avoid an escape character being split between payloads
-		break
-	case pdutext.GSM7Packed:
-		maxLen = 132 // to avoid an escape character being split between payloads
-		break
-	case pdutext.UCS2:
-		maxLen = 132 // to avoid a character being split between payloads
-		break
-	}
-	rawMsg := sm.Text.Encode()
-	countParts := int((len(rawMsg)-1)/maxLen) + 1
-
-	parts := make([]ShortMessage, 0, countParts)
+	return t.SubmitLongMsgContext(context.Background(), sm)
+}
 
-	t.rMutex.Lock()
-	rn := uint16(t.r.Intn(0xFFFF))
-	t.rMutex.Unlock()
-	UDHHeader := make([]byte, 7)
-	UDHHeader[0] = 0x06              // length of user data header
-	UDHHeader[1] = 0x08              // information element identifier, CSMS 16 bit reference number
-	UDHHeader[2] = 0x04              // length of remaining header
-	UDHHeader[3] = uint8(rn >> 8)    // most significant byte of the reference number
-	UDHHeader[4] = uint8(rn)         // least significant byte of the reference number
-	UDHHeader[5] = uint8(countParts) // total number of message parts
-	for i := 0; i < countParts; i++ {
-		UDHHeader[6] = uint8(i + 1) // current message part
-		p := pdu.NewSubmitSM(sm.TLVFields)
-		f := p.Fields()
-		f.Set(pdufield.SourceAddr, sm.Src)
-		f.Set(pdufield.DestinationAddr, sm.Dst)
-		if i != countParts-1 {
-			f.Set(pdufield.ShortMessage, pdutext.Raw(append(UDHHeader, rawMsg[i*maxLen:(i+1)*maxLen]...)))
-		} else {
-			f.Set(pdufield.ShortMessage, pdutext.Raw(append(UDHHeader, rawMsg[i*maxLen:]...)))
+// SubmitLongMsgContext is like SubmitLongMsg but takes a context that
+// can cancel the submission. Parts are sent one at a time, and once ctx
+// is done no further parts are sent and ctx.Err() is returned.
+func (t *Transmitter) SubmitLongMsgContext(ctx context.Context, sm *ShortMessage) ([]ShortMessage, error) {
+	split := sm.Split
+	if split == SplitDefault {
+		split = t.Split
+	}
+	if _, err := smDataCoding(sm); err != nil {
+		return nil, err
+	}
+	var pdus []pdu.Body
+	if split == SplitPayload {
+		text, esm, err := shortMessage(sm)
+		if err != nil {
+			return nil, err
 		}
-		f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
-		if sm.Validity != time.Duration(0) {
-			f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
+		p := newLongMsgPart(sm, esm, nil)
+		p.TLVFields().Set(pdutlv.TagMessagePayload, text.Encode())
+		pdus = append(pdus, p)
+	} else {
+		var err error
+		if pdus, err = t.splitLongMsg(sm, split); err != nil {
+			return nil, err
 		}
-		f.Set(pdufield.ServiceType, sm.ServiceType)
-		f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
-		f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
-		f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
-		f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
-		f.Set(pdufield.ESMClass, 0x40)
-		f.Set(pdufield.ProtocolID, sm.ProtocolID)
-		f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
-		f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
-		f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
-		f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
-		f.Set(pdufield.DataCoding, uint8(sm.Text.Type()))
-		resp, err := t.do(p)
+	}
+
+	parts := make([]ShortMessage, 0, len(pdus))
+	for _, p := range pdus {
+		resp, err := t.doTrack(ctx, p, sm)
 		if err != nil {
 			return nil, err
 		}
@@ -418,13 +602,75 @@ func (t *Transmitter) SubmitLongMsg(sm *ShortMessage) ([]ShortMessage, error) {
 	return parts, nil
 }
 
-func (t *Transmitter) submitMsg(sm *ShortMessage, p pdu.Body, dataCoding uint8) (*ShortMessage, error) {
+// splitLongMsg returns the SubmitSM PDUs with the parts of the long
+// message sm, concatenated with the given strategy.
+func (t *Transmitter) splitLongMsg(sm *ShortMessage, split SplitStrategy) ([]pdu.Body, error) {
+	ies, err := smUDH(sm)
+	if err != nil {
+		return nil, err
+	}
+	var concat pduudh.IE
+	switch split {
+	case SplitSAR:
+	case SplitUDH8:
+		concat = pduudh.Concat8{}
+	default:
+		concat = pduudh.Concat16{}
+	}
+	udhLen := ies.Len()
+	if concat != nil {
+		udhLen = append(pduudh.UDH{concat}, ies...).Len()
+	}
+	segments := pdutext.Split(sm.Text, udhLen)
+	countParts := len(segments)
+	if countParts > MaxLongMsgParts {
+		return nil, ErrTooManyParts
+	}
+
+	t.rMutex.Lock()
+	rn := uint16(t.r.Intn(0xFFFF))
+	t.rMutex.Unlock()
+
+	pdus := make([]pdu.Body, 0, countParts)
+	for i, data := range segments {
+		var udh pduudh.UDH
+		switch split {
+		case SplitSAR:
+		case SplitUDH8:
+			udh = pduudh.UDH{pduudh.Concat8{Ref: uint8(rn), Total: uint8(countParts), Seq: uint8(i + 1)}}
+		default:
+			udh = pduudh.UDH{pduudh.Concat16{Ref: rn, Total: uint8(countParts), Seq: uint8(i + 1)}}
+		}
+		udh = append(udh, ies...)
+		esm := sm.ESMClass
+		if len(udh) > 0 {
+			b, err := udh.Marshal()
+			if err != nil {
+				return nil, err
+			}
+			esm |= 0x40
+			data = append(b, data...)
+		}
+		p := newLongMsgPart(sm, esm, data)
+		if split == SplitSAR {
+			tlv := p.TLVFields()
+			tlv.SetUint16(pdutlv.TagSarMsgRefNum, rn)
+			tlv.SetUint8(pdutlv.TagSarTotalSegments, uint8(countParts))
+			tlv.SetUint8(pdutlv.TagSarSegmentSeqnum, uint8(i+1))
+		}
+		pdus = append(pdus, p)
+	}
+	return pdus, nil
+}
+
+// newLongMsgPart returns a SubmitSM with a part of the long message sm.
+func newLongMsgPart(sm *ShortMessage, esm uint8, data []byte) pdu.Body {
+	p := pdu.NewSubmitSM(sm.TLVFields)
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, sm.Src)
 	f.Set(pdufield.DestinationAddr, sm.Dst)
-	f.Set(pdufield.ShortMessage, sm.Text)
+	f.Set(pdufield.ShortMessage, pdutext.Raw(data))
 	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
-	// Check if the message has validity set.
 	if sm.Validity != time.Duration(0) {
 		f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
 	}
@@ -433,36 +679,91 @@ func (t *Transmitter) submitMsg(sm *ShortMessage, p pdu.Body, dataCoding uint8)
 	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
 	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
 	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
-	f.Set(pdufield.ESMClass, sm.ESMClass)
+	f.Set(pdufield.ESMClass, esm)
 	f.Set(pdufield.ProtocolID, sm.ProtocolID)
 	f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
 	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
 	f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
 	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
+	dataCoding, _ := smDataCoding(sm) // checked by SubmitLongMsgContext
 	f.Set(pdufield.DataCoding, dataCoding)
-	resp, err := t.do(p)
+	return p
+}
+
+// smDataCoding returns the data_coding of sm: the data coding scheme
+// set in sm with the alphabet of its text codec, or the type of the
+// codec if not set.
+func smDataCoding(sm *ShortMessage) (uint8, error) {
+	if sm.DCS == nil {
+		return uint8(sm.Text.Type()), nil
+	}
+	d := *sm.DCS
+	d.Alphabet = sm.Text.Type()
+	c, err := d.DataCoding()
+	return uint8(c), err
+}
+
+// smUDH returns the information elements of the user data header of
+// sm: those set in sm, followed by those required by the text codec.
+func smUDH(sm *ShortMessage) (pduudh.UDH, error) {
+	c, ok := sm.Text.(pdutext.UDHCodec)
+	if !ok {
+		return sm.UDH, nil
+	}
+	ies, err := pduudh.Parse(c.UDH())
 	if err != nil {
 		return nil, err
 	}
-	sm.resp.Lock()
-	sm.resp.p = resp.PDU
-	sm.resp.Unlock()
-	if resp.PDU == nil {
-		return nil, fmt.Errorf("unexpected empty PDU")
+	return append(sm.UDH[:len(sm.UDH):len(sm.UDH)], ies...), nil
+}
+
+// shortMessage returns the short message data of sm and its esm_class,
+// with the user data header of sm, if any.
+func shortMessage(sm *ShortMessage) (pdutext.Codec, uint8, error) {
+	udh, err := smUDH(sm)
+	if err != nil || len(udh) == 0 {
+		return sm.Text, sm.ESMClass, err
 	}
-	if id := resp.PDU.Header().ID; id != pdu.SubmitSMRespID {
-		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
+	data, err := udh.Marshal()
+	if err != nil {
+		return nil, 0, err
 	}
-	if s := resp.PDU.Header().Status; s != 0 {
-		return sm, s
+	return pdutext.Raw(append(data, sm.Text.Encode()...)), sm.ESMClass | 0x40, nil
+}
+
+func setSubmitFields(sm *ShortMessage, p pdu.Body, dataCoding uint8) error {
+	text, esm, err := shortMessage(sm)
+	if err != nil {
+		return err
 	}
-	return sm, resp.Err
+	f := p.Fields()
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.DestinationAddr, sm.Dst)
+	f.Set(pdufield.ShortMessage, text)
+	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
+	// Check if the message has validity set.
+	if sm.Validity != time.Duration(0) {
+		f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
+	}
+	f.Set(pdufield.ServiceType, sm.ServiceType)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
+	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
+	f.Set(pdufield.ESMClass, esm)
+	f.Set(pdufield.ProtocolID, sm.ProtocolID)
+	f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
+	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
+	f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
+	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
+	f.Set(pdufield.DataCoding, dataCoding)
+	return nil
 }
 
-func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding uint8) (*ShortMessage, error) {
+func setSubmitMultiFields(sm *ShortMessage, p pdu.Body, dataCoding uint8) error {
 	numberOfDest := len(sm.DstList) + len(sm.DLs) // TODO: Validate numbers and lists according to size
 	if numberOfDest > MaxDestinationAddress {
-		return nil, fmt.Errorf("Error: Max number of destination addresses allowed is %d, trying to send to %d",
+		return fmt.Errorf("Error: Max number of destination addresses allowed is %d, trying to send to %d",
 			MaxDestinationAddress, numberOfDest)
 	}
 	// Put destination addresses and lists inside an byte array
@@ -487,10 +788,14 @@ func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding ui
 		bArray = append(bArray, byte(0x00))
 	}
 
+	text, esm, err := shortMessage(sm)
+	if err != nil {
+		return err
+	}
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, sm.Src)
 	f.Set(pdufield.DestinationList, bArray)
-	f.Set(pdufield.ShortMessage, sm.Text)
+	f.Set(pdufield.ShortMessage, text)
 	f.Set(pdufield.NumberDests, uint8(numberOfDest))
 	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
 	// Check if the message has validity set.
@@ -500,30 +805,97 @@ func (t *Transmitter) submitMsgMulti(sm *ShortMessage, p pdu.Body, dataCoding ui
 	f.Set(pdufield.ServiceType, sm.ServiceType)
 	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
 	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
-	f.Set(pdufield.ESMClass, sm.ESMClass)
+	f.Set(pdufield.ESMClass, esm)
 	f.Set(pdufield.ProtocolID, sm.ProtocolID)
 	f.Set(pdufield.PriorityFlag, sm.PriorityFlag)
 	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
 	f.Set(pdufield.ReplaceIfPresentFlag, sm.ReplaceIfPresentFlag)
 	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
 	f.Set(pdufield.DataCoding, dataCoding)
-	resp, err := t.do(p)
+	return nil
+}
+
+// DataResp contains the parsed response of a DataSM request.
+//
+// The delivery failure reason, network error code and additional status
+// info are optional TLVs that the SMSC normally sets when delivery fails.
+type DataResp struct {
+	MsgID                 string
+	DeliveryFailureReason uint8
+	NetworkType           uint8
+	NetworkErrorCode      uint16
+	AdditionalStatusInfo  string
+}
+
+// newDataResp returns a new DataResp constructed from a DataSMResp PDU.
+func newDataResp(p pdu.Body) *DataResp {
+	dr := &DataResp{}
+	if f := p.Fields()[pdufield.MessageID]; f != nil {
+		dr.MsgID = f.String()
+	}
+	tlv := p.TLVFields()
+	if f := tlv[pdutlv.TagDeliveryFailureReason]; f != nil && len(f.Bytes()) > 0 {
+		dr.DeliveryFailureReason = f.Bytes()[0]
+	}
+	if f := tlv[pdutlv.TagNetworkErrorCode]; f != nil && len(f.Bytes()) == 3 {
+		dr.NetworkType = f.Bytes()[0]
+		dr.NetworkErrorCode = binary.BigEndian.Uint16(f.Bytes()[1:])
+	}
+	if f := tlv[pdutlv.TagAdditionalStatusInfoText]; f != nil {
+		dr.AdditionalStatusInfo = f.String()
+	}
+	return dr
+}
+
+// SubmitData sends a message using the DataSM operation, with the
+// encoded text in the message_payload TLV, and updates the given sm
+// with the response PDU.
+//
+// The returned DataResp is also set when the SMSC responds with an
+// error status, which is returned as the error.
+func (t *Transmitter) SubmitData(sm *ShortMessage) (*DataResp, error) {
+	return t.SubmitDataContext(context.Background(), sm)
+}
+
+// SubmitDataContext is like SubmitData but takes a context that can
+// cancel the rate limiter wait and the wait for the response.
+func (t *Transmitter) SubmitDataContext(ctx context.Context, sm *ShortMessage) (*DataResp, error) {
+	dataCoding, err := smDataCoding(sm)
+	if err != nil {
+		return nil, err
+	}
+	text, esm, err := shortMessage(sm)
+	if err != nil {
+		return nil, err
+	}
+	p := pdu.NewDataSM(sm.TLVFields)
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, sm.ServiceType)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
+	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
+	f.Set(pdufield.DestinationAddr, sm.Dst)
+	f.Set(pdufield.ESMClass, esm)
+	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
+	f.Set(pdufield.DataCoding, dataCoding)
+	p.TLVFields().Set(pdutlv.TagMessagePayload, text.Encode())
+	resp, err := t.doTrack(ctx, p, sm)
 	if err != nil {
 		return nil, err
 	}
 	sm.resp.Lock()
 	sm.resp.p = resp.PDU
 	sm.resp.Unlock()
-	if resp.PDU == nil {
-		return nil, fmt.Errorf("unexpected empty PDU")
-	}
-	if id := resp.PDU.Header().ID; id != pdu.SubmitMultiRespID {
-		return sm, fmt.Errorf("unexpected PDU ID: %s", id)
+	if id := resp.PDU.Header().ID; id != pdu.DataSMRespID {
+		return nil, fmt.Errorf("unexpected PDU ID: %s", id)
 	}
+	dr := newDataResp(resp.PDU)
 	if s := resp.PDU.Header().Status; s != 0 {
-		return sm, s
+		return dr, s
 	}
-	return sm, resp.Err
+	return dr, nil
 }
 
 // QueryResp contains the parsed the response of a QuerySM request.
@@ -537,6 +909,12 @@ type QueryResp struct {
 // QuerySM queries the delivery status of a message. It requires the
 // source address (sender) with TON and NPI and message ID.
 func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryResp, error) {
+	return t.QuerySMContext(context.Background(), src, msgid, srcTON, srcNPI)
+}
+
+// QuerySMContext is like QuerySM but takes a context that can cancel
+// the rate limiter wait and the wait for the response.
+func (t *Transmitter) QuerySMContext(ctx context.Context, src, msgid string, srcTON, srcNPI uint8) (*QueryResp, error) {
 	p := pdu.NewQuerySM()
 	f := p.Fields()
 	f.Set(pdufield.SourceAddr, src)
@@ -544,7 +922,7 @@ func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryRe
 	f.Set(pdufield.SourceAddrNPI, srcNPI)
 	f.Set(pdufield.MessageID, msgid)
 
-	resp, err := t.do(p)
+	resp, err := t.do(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -593,6 +971,85 @@ func (t *Transmitter) QuerySM(src, msgid string, srcTON, srcNPI uint8) (*QueryRe
 	return qr, nil
 }
 
+// CancelSM cancels a previously submitted short message that is still
+// pending delivery. The source and destination addresses (with TON and
+// NPI) and the service type are taken from the given sm.
+//
+// If msgid is empty, all pending messages matching the source address,
+// destination address and service type are cancelled.
+func (t *Transmitter) CancelSM(sm *ShortMessage, msgid string) error {
+	return t.CancelSMContext(context.Background(), sm, msgid)
+}
+
+// CancelSMContext is like CancelSM but takes a context that can cancel
+// the rate limiter wait and the wait for the response.
+func (t *Transmitter) CancelSMContext(ctx context.Context, sm *ShortMessage, msgid string) error {
+	p := pdu.NewCancelSM()
+	f := p.Fields()
+	f.Set(pdufield.ServiceType, sm.ServiceType)
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.DestAddrTON, sm.DestAddrTON)
+	f.Set(pdufield.DestAddrNPI, sm.DestAddrNPI)
+	f.Set(pdufield.DestinationAddr, sm.Dst)
+
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.CancelSMRespID {
+		return fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return s
+	}
+	return nil
+}
+
+// ReplaceSM replaces the text and delivery settings of a previously
+// submitted short message that is still pending delivery. The source
+// address (with TON and NPI), schedule, validity and registered delivery
+// are taken from the given sm, and the new text from its Text codec.
+//
+// The SMSC keeps the data_coding of the original message, so the codec
+// must be of the same type used when the message was submitted.
+func (t *Transmitter) ReplaceSM(sm *ShortMessage, msgid string) error {
+	return t.ReplaceSMContext(context.Background(), sm, msgid)
+}
+
+// ReplaceSMContext is like ReplaceSM but takes a context that can
+// cancel the rate limiter wait and the wait for the response.
+func (t *Transmitter) ReplaceSMContext(ctx context.Context, sm *ShortMessage, msgid string) error {
+	p := pdu.NewReplaceSM()
+	f := p.Fields()
+	f.Set(pdufield.MessageID, msgid)
+	f.Set(pdufield.SourceAddrTON, sm.SourceAddrTON)
+	f.Set(pdufield.SourceAddrNPI, sm.SourceAddrNPI)
+	f.Set(pdufield.SourceAddr, sm.Src)
+	f.Set(pdufield.ScheduleDeliveryTime, sm.ScheduleDeliveryTime)
+	if sm.Validity != time.Duration(0) {
+		f.Set(pdufield.ValidityPeriod, convertValidity(sm.Validity))
+	}
+	f.Set(pdufield.RegisteredDelivery, uint8(sm.Register))
+	f.Set(pdufield.SMDefaultMsgID, sm.SMDefaultMsgID)
+	// replace_sm has no data_coding field, set the encoded text only.
+	f.Set(pdufield.ShortMessage, sm.Text.Encode())
+
+	resp, err := t.do(ctx, p)
+	if err != nil {
+		return err
+	}
+	if id := resp.PDU.Header().ID; id != pdu.ReplaceSMRespID {
+		return fmt.Errorf("unexpected PDU ID: %s", id)
+	}
+	if s := resp.PDU.Header().Status; s != 0 {
+		return s
+	}
+	return nil
+}
+
 func convertValidity(d time.Duration) string {
 	validity := time.Now().UTC().Add(d)
 	// Absolute time format YYMMDDhhmmsstnnp, see SMPP3.4 spec 7.1.1.
@@ -5,15 +5,21 @@
 package smpp
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"golang.org/x/time/rate"
 
+	"github.com/fiorix/go-smpp/smpp/encoding"
 	"github.com/fiorix/go-smpp/smpp/pdu"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdufield"
 	"github.com/fiorix/go-smpp/smpp/pdu/pdutext"
+	"github.com/fiorix/go-smpp/smpp/pdu/pdutlv"
+	"github.com/fiorix/go-smpp/smpp/pdu/pduudh"
 	"github.com/fiorix/go-smpp/smpp/smpptest"
 )
 
@@ -120,6 +126,172 @@ func TestShortMessageWindowSize(t *testing.T) {
 	}
 }
 
+func TestShortMessageWindowWait(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		time.Sleep(100 * time.Millisecond)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		WindowSize:  1,
+		WindowWait:  true,
+		RespTimeout: time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	errc := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := tx.Submit(&ShortMessage{
+				Src:      "root",
+				Dst:      "foobar",
+				Text:     pdutext.Raw("Lorem ipsum"),
+				Register: pdufield.NoDeliveryReceipt,
+			})
+			errc <- err
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	st := tx.WindowStats()
+	if st.Size != 1 || st.Inflight != 0 || st.Waiting != 0 {
+		t.Fatalf("unexpected window stats: %+v", st)
+	}
+	if st.Waits != 2 || st.WaitTime < 100*time.Millisecond {
+		t.Fatalf("unexpected window wait stats: %+v", st)
+	}
+}
+
+func TestSubmitAsync(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, p.Fields()[pdufield.DestinationAddr].String())
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:       s.Addr(),
+		User:       smpptest.DefaultUser,
+		Passwd:     smpptest.DefaultPasswd,
+		WindowSize: 2,
+		WindowWait: true,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	const n = 10
+	rc := make(chan *ShortMessage, n)
+	for i := 0; i < n; i++ {
+		err := tx.SubmitAsync(&ShortMessage{
+			Src:      "root",
+			Dst:      fmt.Sprintf("%d", i),
+			Text:     pdutext.Raw("Lorem ipsum"),
+			Register: pdufield.NoDeliveryReceipt,
+		}, func(sm *ShortMessage, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			rc <- sm
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case sm := <-rc:
+			if sm == nil {
+				t.Fatal("missing response")
+			}
+			if id := sm.RespID(); id != sm.Dst {
+				t.Fatalf("unexpected msgid: want %q, have %q", sm.Dst, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for responses")
+		}
+	}
+	if st := tx.WindowStats(); st.Inflight != 0 {
+		t.Fatalf("unexpected # of inflight requests: want 0, have %d", st.Inflight)
+	}
+}
+
+func TestSubmitContext(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		time.Sleep(200 * time.Millisecond)
+		r := pdu.NewSubmitSMResp()
+		r.Header().Seq = p.Header().Seq
+		r.Fields().Set(pdufield.MessageID, "foobar")
+		c.Write(r)
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:        s.Addr(),
+		User:        smpptest.DefaultUser,
+		Passwd:      smpptest.DefaultPasswd,
+		RespTimeout: time.Second,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{
+		Src:      "root",
+		Dst:      "foobar",
+		Text:     pdutext.Raw("Lorem ipsum"),
+		Register: pdufield.NoDeliveryReceipt,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := tx.SubmitContext(ctx, sm)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+	_, err = tx.SubmitContext(ctx, sm)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+	_, err = tx.SubmitContext(context.Background(), sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgid := sm.RespID(); msgid != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", msgid)
+	}
+}
+
 func TestLongMessage(t *testing.T) {
 	s := smpptest.NewUnstartedServer()
 	count := 0
@@ -242,6 +414,296 @@ func TestLongMessageAsUCS2(t *testing.T) {
 	}
 }
 
+func TestSubmitLongMsgSplit(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+		Split:  SplitUDH8,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	text := strings.Repeat("Lorem ipsum dolor sit amet. ", 10)
+	test := []struct {
+		split SplitStrategy
+		parts int
+	}{
+		{SplitDefault, 3}, // Transmitter's
+		{SplitUDH16, 3},
+		{SplitUDH8, 3},
+		{SplitSAR, 2},
+		{SplitPayload, 1},
+	}
+	for _, tc := range test {
+		parts, err := tx.SubmitLongMsg(&ShortMessage{
+			Src:   "root",
+			Dst:   "foobar",
+			Text:  pdutext.Latin1(text),
+			Split: tc.split,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != tc.parts {
+			t.Fatalf("split %d: unexpected number of parts: want %d, have %d",
+				tc.split, tc.parts, len(parts))
+		}
+		r := &Receiver{MergeInterval: time.Minute}
+		r.mg.store = NewMemoryStore()
+		merged := false
+		for i := 0; i < tc.parts; i++ {
+			p := <-rc
+			if tc.split == SplitPayload {
+				payload := p.TLVFields()[pdutlv.TagMessagePayload]
+				if payload == nil || string(payload.Bytes()) != text {
+					t.Fatalf("unexpected message_payload: %#v", payload)
+				}
+				if n := len(p.Fields()[pdufield.ShortMessage].Bytes()); n != 0 {
+					t.Fatalf("unexpected short message length: %d", n)
+				}
+				continue
+			}
+			esm := p.Fields()[pdufield.ESMClass].Bytes()[0]
+			if udhi := esm&0x40 != 0; udhi == (tc.split == SplitSAR) {
+				t.Fatalf("split %d: unexpected esm_class: %#x", tc.split, esm)
+			}
+			if m, _ := r.merge(p); m != nil {
+				if m.Text != text {
+					t.Fatalf("split %d: unexpected message: %q", tc.split, m.Text)
+				}
+				merged = true
+			}
+		}
+		if !merged && tc.split != SplitPayload {
+			t.Fatalf("split %d: message not merged", tc.split)
+		}
+	}
+}
+
+func TestSubmitNationalLanguage(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	text := pdutext.GSM7National{
+		Text:    []byte("Günaydın, nasılsın?"),
+		Locking: encoding.Turkish,
+		Single:  encoding.Turkish,
+	}
+	if _, err := tx.Submit(&ShortMessage{Src: "root", Dst: "foobar", Text: text}); err != nil {
+		t.Fatal(err)
+	}
+	p := <-rc
+	f := p.Fields()
+	if esm := f[pdufield.ESMClass].Bytes()[0]; esm&0x40 == 0 {
+		t.Fatalf("unexpected esm_class: %#x", esm)
+	}
+	udh := []byte{6, 0x24, 1, 1, 0x25, 1, 1}
+	if sm := f[pdufield.ShortMessage].Bytes(); !bytes.HasPrefix(sm, udh) {
+		t.Fatalf("unexpected short message: %x", sm)
+	}
+	text.Text = []byte(strings.Repeat("Günaydın, nasılsın? ", 10))
+	parts, err := tx.SubmitLongMsg(&ShortMessage{Src: "root", Dst: "foobar", Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Receiver{MergeInterval: time.Minute}
+	r.mg.store = NewMemoryStore()
+	var m *MergedMessage
+	for range parts {
+		m, _ = r.merge(<-rc)
+	}
+	if m == nil {
+		t.Fatal("message not merged")
+	}
+	if m.Text != string(text.Text) {
+		t.Fatalf("unexpected message: %q", m.Text)
+	}
+}
+
+func TestSubmitDCS(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	test := []struct {
+		text pdutext.Codec
+		dcs  *pdutext.DCS
+		want uint8
+	}{
+		{pdutext.Latin1("Olá"), nil, 0x03},
+		{pdutext.GSM7("Flash"), &pdutext.DCS{Class: pdutext.Class0}, 0x10},
+		{pdutext.UCS2("Olá"), &pdutext.DCS{Class: pdutext.Class0}, 0x18},
+		{pdutext.GSM7(""), &pdutext.DCS{Group: pdutext.DiscardGroup, Active: true}, 0xC8},
+	}
+	for _, tc := range test {
+		sm := &ShortMessage{Src: "root", Dst: "foobar", Text: tc.text, DCS: tc.dcs}
+		if _, err := tx.Submit(sm); err != nil {
+			t.Fatal(err)
+		}
+		p := <-rc
+		if c := p.Fields()[pdufield.DataCoding].Bytes()[0]; c != tc.want {
+			t.Fatalf("unexpected data_coding: want %#x, have %#x", tc.want, c)
+		}
+	}
+	// Latin1 can't be sent as a flash message.
+	sm := &ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Latin1("Olá"),
+		DCS:  &pdutext.DCS{Class: pdutext.Class0},
+	}
+	if _, err := tx.Submit(sm); err == nil {
+		t.Fatal("want error for invalid DCS, have nil")
+	}
+	if _, err := tx.SubmitLongMsg(sm); err == nil {
+		t.Fatal("want error for invalid DCS, have nil")
+	}
+}
+
+func TestSubmitUDH(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan pdu.Body, 10)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.SubmitSMID:
+			rc <- p
+			r := pdu.NewSubmitSMResp()
+			r.Header().Seq = p.Header().Seq
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	port := pduudh.Port16{Dst: 2948, Src: 9200}
+	sm := &ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Binary("\x01\x06\x04"),
+		UDH:  pduudh.UDH{port},
+	}
+	if _, err := tx.Submit(sm); err != nil {
+		t.Fatal(err)
+	}
+	p := <-rc
+	f := p.Fields()
+	if esm := f[pdufield.ESMClass].Bytes()[0]; esm&0x40 == 0 {
+		t.Fatalf("unexpected esm_class: %#x", esm)
+	}
+	want := []byte{6, 0x05, 4, 0x0B, 0x84, 0x23, 0xF0, 0x01, 0x06, 0x04}
+	if data := f[pdufield.ShortMessage].Bytes(); !bytes.Equal(data, want) {
+		t.Fatalf("unexpected short message:\nwant: %x\nhave: %x", want, data)
+	}
+	sm.Text = pdutext.Binary(bytes.Repeat([]byte{0xAA}, 200))
+	parts, err := tx.SubmitLongMsg(sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("unexpected number of parts: want 2, have %d", len(parts))
+	}
+	for i := range parts {
+		udh, data, err := pduudh.Unmarshal((<-rc).Fields()[pdufield.ShortMessage].Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, ok := udh.Find(pduudh.IEIConcat16).(pduudh.Concat16)
+		if !ok || c.Total != 2 || int(c.Seq) != i+1 {
+			t.Fatalf("unexpected concatenation: %#v", udh)
+		}
+		if udh.Find(pduudh.IEIPort16) != port {
+			t.Fatalf("unexpected port: %#v", udh)
+		}
+		if l := len(data); l != []int{127, 73}[i] {
+			t.Fatalf("unexpected length of part %d: %d", i, l)
+		}
+	}
+}
+
 func TestQuerySM(t *testing.T) {
 	s := smpptest.NewUnstartedServer()
 	s.Handler = func(c smpptest.Conn, p pdu.Body) {
@@ -385,3 +847,157 @@ func TestNotConnected(t *testing.T) {
 	}
 
 }
+
+func TestCancelSM(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.CancelSMID:
+			r := pdu.NewCancelSMResp()
+			r.Header().Seq = p.Header().Seq
+			if p.Fields()[pdufield.MessageID].String() != "13" {
+				r.Header().Status = 0x0000000c // invalid message id
+			}
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	sm := &ShortMessage{Src: "root", Dst: "foobar"}
+	if err := tx.CancelSM(sm, "13"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.CancelSM(sm, "14"); err != pdu.Status(0x0000000c) {
+		t.Fatalf("unexpected error: want invalid message id, have %v", err)
+	}
+}
+
+func TestReplaceSM(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	rc := make(chan string, 1)
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.ReplaceSMID:
+			r := pdu.NewReplaceSMResp()
+			r.Header().Seq = p.Header().Seq
+			c.Write(r)
+			rc <- string(pdutext.UCS2(p.Fields()[pdufield.ShortMessage].Bytes()).Decode())
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	err := tx.ReplaceSM(&ShortMessage{
+		Src:      "root",
+		Text:     pdutext.UCS2("Olá mundão"),
+		Validity: 10 * time.Minute,
+	}, "13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := <-rc; text != "Olá mundão" {
+		t.Fatalf("unexpected text: want %q, have %q", "Olá mundão", text)
+	}
+}
+
+func TestSubmitData(t *testing.T) {
+	s := smpptest.NewUnstartedServer()
+	s.Handler = func(c smpptest.Conn, p pdu.Body) {
+		switch p.Header().ID {
+		case pdu.DataSMID:
+			r := pdu.NewDataSMResp(p.Header().Seq, "")
+			r.Fields().Set(pdufield.MessageID, "foobar")
+			payload := p.TLVFields()[pdutlv.TagMessagePayload]
+			if payload == nil || payload.String() != "Lorem ipsum" {
+				r.Header().Status = 0xfe
+				r.TLVFields().Set(pdutlv.TagDeliveryFailureReason, uint8(2))
+				r.TLVFields().Set(pdutlv.TagNetworkErrorCode, []byte{0x03, 0x00, 0x22})
+			}
+			c.Write(r)
+		default:
+			smpptest.EchoHandler(c, p)
+		}
+	}
+	s.Start()
+	defer s.Close()
+	tx := &Transmitter{
+		Addr:   s.Addr(),
+		User:   smpptest.DefaultUser,
+		Passwd: smpptest.DefaultPasswd,
+	}
+	defer tx.Close()
+	conn := <-tx.Bind()
+	switch conn.Status() {
+	case Connected:
+	default:
+		t.Fatal(conn.Error())
+	}
+	dr, err := tx.SubmitData(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("Lorem ipsum"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dr.MsgID != "foobar" {
+		t.Fatalf("unexpected msgid: want foobar, have %q", dr.MsgID)
+	}
+	dr, err = tx.SubmitData(&ShortMessage{
+		Src:  "root",
+		Dst:  "foobar",
+		Text: pdutext.Raw("dolor sit amet"),
+	})
+	if err != pdu.Status(0xfe) {
+		t.Fatalf("unexpected error: want delivery failure, have %v", err)
+	}
+	if dr.DeliveryFailureReason != 2 {
+		t.Fatalf("unexpected delivery failure reason: want 2, have %d", dr.DeliveryFailureReason)
+	}
+	if dr.NetworkType != 3 || dr.NetworkErrorCode != 0x22 {
+		t.Fatalf("unexpected network error code: %#v", dr)
+	}
+}
+
+func TestSubmitLongMsgTooManyParts(t *testing.T) {
+	tx := &Transmitter{}
+	text := strings.Repeat("a", 160*MaxLongMsgParts+1)
+	for _, split := range []SplitStrategy{SplitUDH8, SplitUDH16, SplitSAR} {
+		_, err := tx.SubmitLongMsg(&ShortMessage{
+			Src:   "root",
+			Dst:   "foobar",
+			Text:  pdutext.GSM7(text),
+			Split: split,
+		})
+		if err != ErrTooManyParts {
+			t.Fatalf("unexpected error for split %d: want %v, have %v", split, ErrTooManyParts, err)
+		}
+	}
+}
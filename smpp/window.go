@@ -0,0 +1,130 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// WindowStats contains the window usage of a Transmitter or
+// Transceiver, as returned by their WindowStats method.
+type WindowStats struct {
+	Size     uint          // Configured window size.
+	Inflight uint          // Requests waiting for their response.
+	Waiting  uint          // Requests waiting for a free window slot.
+	Waits    uint64        // Requests that had to wait for a slot.
+	Timeouts uint64        // Requests that timed out waiting for a slot.
+	Canceled uint64        // Requests whose context was canceled while waiting for a slot.
+	WaitTime time.Duration // Total time spent waiting for slots.
+	MaxWait  time.Duration // Longest time spent waiting for a slot.
+}
+
+// window is a counting semaphore that limits the number of inflight
+// requests. Requests waiting for a slot are served in FIFO order.
+type window struct {
+	mu      sync.Mutex
+	size    uint
+	n       uint
+	waiters list.List // of chan struct{}
+	stats   WindowStats
+}
+
+func newWindow(size uint) *window {
+	return &window{size: size}
+}
+
+// tryAcquire takes a slot without waiting, and returns false if
+// the window is full.
+func (w *window) tryAcquire() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.n < w.size && w.waiters.Len() == 0 {
+		w.n++
+		return true
+	}
+	return false
+}
+
+// acquire takes a slot, waiting behind any earlier requests until one
+// is released. It returns ErrMaxWindowSize if timeout fires, or
+// ctx.Err() if ctx is done first.
+func (w *window) acquire(ctx context.Context, timeout <-chan time.Time) error {
+	if w.tryAcquire() {
+		return nil
+	}
+	start := time.Now()
+	ready := make(chan struct{})
+	w.mu.Lock()
+	if w.n < w.size && w.waiters.Len() == 0 {
+		// A slot was released since tryAcquire.
+		w.n++
+		w.mu.Unlock()
+		return nil
+	}
+	elem := w.waiters.PushBack(ready)
+	w.mu.Unlock()
+	var err error
+	select {
+	case <-ready:
+	case <-timeout:
+		err = ErrMaxWindowSize
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		select {
+		case <-ready:
+			// Granted while giving up, pass the slot on.
+			w.releaseLocked()
+		default:
+			w.waiters.Remove(elem)
+		}
+		if err == context.Canceled {
+			w.stats.Canceled++
+		} else {
+			w.stats.Timeouts++
+		}
+		return err
+	}
+	d := time.Since(start)
+	w.stats.Waits++
+	w.stats.WaitTime += d
+	if d > w.stats.MaxWait {
+		w.stats.MaxWait = d
+	}
+	return nil
+}
+
+// release returns a slot, handing it over to the oldest waiter if any.
+func (w *window) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.releaseLocked()
+}
+
+func (w *window) releaseLocked() {
+	if front := w.waiters.Front(); front != nil {
+		w.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	w.n--
+}
+
+// Stats returns a snapshot of the window usage.
+func (w *window) Stats() WindowStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.stats
+	st.Size = w.size
+	st.Inflight = w.n
+	st.Waiting = uint(w.waiters.Len())
+	return st
+}
@@ -0,0 +1,69 @@
+// Copyright 2015 go-smpp authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package smpp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowFIFO(t *testing.T) {
+	w := newWindow(1)
+	if !w.tryAcquire() {
+		t.Fatal("failed to acquire empty window")
+	}
+	if w.tryAcquire() {
+		t.Fatal("acquired full window")
+	}
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			if err := w.acquire(context.Background(), nil); err != nil {
+				t.Error(err)
+			}
+			order <- i
+		}(i)
+		// Wait until the goroutine is queued.
+		for w.Stats().Waiting != uint(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		w.release()
+		if n := <-order; n != i {
+			t.Fatalf("unexpected order: want %d, have %d", i, n)
+		}
+	}
+	w.release()
+	if st := w.Stats(); st.Inflight != 0 || st.Waits != 3 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestWindowCancel(t *testing.T) {
+	w := newWindow(1)
+	w.tryAcquire()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := w.acquire(ctx, nil); err != context.Canceled {
+		t.Fatalf("unexpected error: want %v, have %v", context.Canceled, err)
+	}
+	if err := w.acquire(context.Background(), time.After(10*time.Millisecond)); err != ErrMaxWindowSize {
+		t.Fatalf("unexpected error: want %v, have %v", ErrMaxWindowSize, err)
+	}
+	dctx, dcancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer dcancel()
+	if err := w.acquire(dctx, nil); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: want %v, have %v", context.DeadlineExceeded, err)
+	}
+	if st := w.Stats(); st.Waiting != 0 || st.Timeouts != 2 || st.Canceled != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+	w.release()
+	if !w.tryAcquire() {
+		t.Fatal("failed to acquire released window")
+	}
+}